@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"gohelp/internal/service/apperr"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-playground/validator"
+)
+
+// ErrorResponse is the body of every failed request. Code is stable and
+// meant for programs, Message is meant for people.
+type ErrorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// FieldError describes one field of the request that failed validation.
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+var errorKinds = []struct {
+	kind   error
+	code   string
+	status int
+}{
+	{apperr.ErrValidation, "validation_failed", http.StatusBadRequest},
+	{apperr.ErrUnauthorized, "unauthorized", http.StatusUnauthorized},
+	{apperr.ErrBanned, "banned", http.StatusForbidden},
+	{apperr.ErrForbidden, "forbidden", http.StatusForbidden},
+	{apperr.ErrNotFound, "not_found", http.StatusNotFound},
+	{apperr.ErrConflict, "conflict", http.StatusConflict},
+	{apperr.ErrUnsupportedMediaType, "unsupported_media_type", http.StatusUnsupportedMediaType},
+	{apperr.ErrTooManyRequests, "too_many_requests", http.StatusTooManyRequests},
+}
+
+var errForbidden = apperr.Forbidden("you dont have permissions to do this")
+
+// writeError is the only place that turns an error into a response. The
+// status and code are picked by the kind of err, errors of no known kind
+// are logged and hidden from the client behind internal_error.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	requestID := middleware.GetReqID(r.Context())
+	response := ErrorResponse{
+		Code:      "internal_error",
+		Message:   "internal server error",
+		RequestID: requestID,
+	}
+	status := http.StatusInternalServerError
+	for _, k := range errorKinds {
+		if errors.Is(err, k.kind) {
+			response.Code = k.code
+			response.Message = err.Error()
+			status = k.status
+			break
+		}
+	}
+	if status == http.StatusInternalServerError {
+		log.Printf("request %s: %v", requestID, err)
+	}
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		response.Details = appErr.Details
+		if appErr.RetryAfter > 0 {
+			// Retry-After counts whole seconds, rounded up so that clients
+			// never come back too early.
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// validationError reports the result of validate.Struct with a detail for
+// every failed field.
+func validationError(err error) error {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return apperr.Validation("validation failed: %v", err)
+	}
+	details := make([]FieldError, 0, len(fieldErrors))
+	for _, fe := range fieldErrors {
+		details = append(details, FieldError{Field: fe.Field(), Rule: fe.Tag(), Param: fe.Param()})
+	}
+	return &apperr.Error{
+		Kind:    apperr.ErrValidation,
+		Message: "validation failed: " + err.Error(),
+		Details: details,
+	}
+}
+
+// requestIDHeader returns the id given to the request by RequestID, so that
+// clients can refer to it when reporting a problem.
+func requestIDHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(middleware.RequestIDHeader, middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"gohelp/internal/service/apperr"
+	"gohelp/pkg/pubsub"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// heartbeatEvery keeps proxies from closing idle streams.
+const heartbeatEvery = 25 * time.Second
+
+// streamUser returns the ID of the user the access token belongs to, or 0
+// without a token. EventSource cannot set headers, so the token may come
+// in the access_token query parameter too.
+func (h *Handler) streamUser(r *http.Request) (int, error) {
+	token := r.URL.Query().Get("access_token")
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+		token = strings.TrimPrefix(authHeader, "Bearer ")
+		if token == authHeader {
+			return 0, apperr.Unauthorized("invalid token format")
+		}
+	}
+	if token == "" {
+		return 0, nil
+	}
+	payload, err := h.Users.Authenticate(r.Context(), token)
+	if err != nil {
+		return 0, err
+	}
+	return payload.UserID, nil
+}
+
+// @Summary Event stream
+// @Tags events
+// @Description Server-Sent Events of a discussion: comment.created, comment.edited, comment.deleted and votes.changed. With an access token the stream carries the notification events of the user too. Either discussion_id or a token is required.
+// @Produce  text/event-stream
+// @Param discussion_id query string false "Discussion ID"
+// @Param access_token query string false "Access token, for clients that cannot set the Authorization header"
+// @Router /events [get]
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.streamUser(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var topics []string
+	if discussionID := r.URL.Query().Get("discussion_id"); discussionID != "" {
+		if err = h.Forum.StreamableDiscussion(r.Context(), discussionID); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		topics = append(topics, pubsub.DiscussionTopic(discussionID))
+	}
+	if userID != 0 {
+		topics = append(topics, pubsub.UserTopic(userID))
+	}
+	if len(topics) == 0 {
+		writeError(w, r, apperr.Validation("discussion_id or an access token is required"))
+		return
+	}
+
+	sub := h.events.Subscribe(topics...)
+	defer sub.Close()
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err = rc.Flush(); err != nil {
+		log.Printf("event stream cannot flush: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatEvery)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		case event := <-sub.Events:
+			var data []byte
+			if data, err = json.Marshal(event.Data); err != nil {
+				log.Printf("failed to encode %s event: %v", event.Type, err)
+				continue
+			}
+			_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
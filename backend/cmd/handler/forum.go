@@ -3,125 +3,237 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
 	"gohelp/util"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/go-playground/validator"
 )
 
 type Forum interface {
-	CreateDiscussion(ctx context.Context, title, content string, AuthorID int) (string, error)
-	CreateComment(ctx context.Context, related_to, discussionID, content string, AuthorID int) (string, error)
+	CreateDiscussion(ctx context.Context, title, content string, tags []string, AuthorID int) (string, string, error)
+	CreateComment(ctx context.Context, related_to, discussionID, content string, AuthorID int) (string, string, error)
 	GetDiscussionWithComments(ctx context.Context, discussionID string) (*models.Discussion, []models.Comment, error)
-	GetAllDiscussionsWithCountOfComments(ctx context.Context) ([]models.DiscussionWithCount, error)
-	SearchDiscussionsByName(ctx context.Context, searchTerm string) ([]models.Discussion, error)
+	GetAllDiscussionsWithCountOfComments(ctx context.Context, query models.DiscussionListQuery) ([]models.DiscussionWithCount, string, error)
+	GetPublicProfile(ctx context.Context, userID int) (*models.PublicProfile, error)
+	GetUserDiscussions(ctx context.Context, userID, limit int, cursor string) ([]models.DiscussionTopic, string, error)
+	GetUserComments(ctx context.Context, userID, limit int, cursor string) ([]models.Comment, string, error)
+	SearchDiscussionsByName(ctx context.Context, searchTerm string, filter models.DiscussionFilter) ([]models.Discussion, error)
 	Vote(ctx context.Context, userID int, discussionID, voteType string) error
-	UpdateDiscussion(ctx context.Context, discussionID, content string, authorID int) (*models.Discussion, error)
-	UpdateComment(ctx context.Context, commentID, content string, authorID int) (*models.Comment, error)
-	DeleteFullDiscussion(ctx context.Context, commentID string) error
+	UpdateDiscussion(ctx context.Context, discussionID, content string, authorID int) (*models.Discussion, string, error)
+	UpdateComment(ctx context.Context, commentID, content string, authorID int) (*models.Comment, string, error)
+	DeleteFullDiscussion(ctx context.Context, discussionID string, moderatorID int) error
 	DeleteComment(ctx context.Context, commentID, userRole string, authorID int) error
-	DeleteFullHistory(ctx context.Context, userID int) error
+	UpdateDiscussionTags(ctx context.Context, discussionID string, tags []string, authorID int, userRole string) (*models.Discussion, error)
+	GetTags(ctx context.Context) ([]models.Tag, error)
+	CreateTag(ctx context.Context, name, description string) error
+	DescribeTag(ctx context.Context, name, description string) error
+	RenameTag(ctx context.Context, name, newName string) error
+	MergeTag(ctx context.Context, source, target string) error
+	AcceptAnswer(ctx context.Context, discussionID, commentID string, userID int) (*models.Discussion, error)
+	RecomputeReputation(ctx context.Context) error
+	GetRevisions(ctx context.Context, postType, postID string, userID int, userRole string) ([]models.Revision, int, error)
+	DiffRevisions(ctx context.Context, postType, postID string, from, to, userID int, userRole string) ([]util.DiffChunk, error)
+	RollbackPost(ctx context.Context, postType, postID string, version, editorID int) (string, error)
+	AnnouncePost(ctx context.Context, postType, postID string) error
+	StreamableDiscussion(ctx context.Context, discussionID string) error
+	Follow(ctx context.Context, userID int, targetType, targetID string) error
+	Unfollow(ctx context.Context, userID int, targetType, targetID string) error
+	GetSubscriptions(ctx context.Context, userID int) ([]models.Subscription, error)
+	GetFeed(ctx context.Context, userID, limit int, cursor string) ([]models.FeedItem, string, error)
 }
 
 var validate = validator.New()
 
+type CreateDiscussionRequest struct {
+	Title   string   `json:"title" validate:"required,max=35"`
+	Content string   `json:"content" validate:"required,max=70"`
+	Tags    []string `json:"tags" validate:"max=5"`
+}
+
 // @Summary Create New Discussion
 // @Security BearerAuth
 // @Tags discussions
-// @Description You can post new discussion
+// @Description You can post new discussion. A discussion the spam filter flags is held for moderators (202, status "held") and shows up once approved
 // @Accept  json
 // @Produce  json
-// @Param title query string true "Title of discussion"
-// @Param content query string true "Describe your problem here"
+// @Param input body CreateDiscussionRequest true "New discussion"
 // @Router /discuss/discussions [post]
 func (h *Handler) CreateDiscussion(w http.ResponseWriter, r *http.Request) {
 	log.Println("CreateDisc func running")
-	request := struct {
-		Title   string `json:"title" validate:"required,max=35"`
-		Content string `json:"content" validate:"required,max=70"`
-	}{
-		Title:   r.URL.Query().Get("title"),
-		Content: r.URL.Query().Get("content"),
+	var request CreateDiscussionRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.Title = q.Get("title")
+		request.Content = q.Get("content")
+		request.Tags = util.ParseTags(q.Get("tags"))
+	}) {
+		return
 	}
+	request.Tags = util.NormalizeTags(request.Tags)
 
 	AuthorID := r.Context().Value(UserIDKey).(int)
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
 	if err := util.ValidateTitle(request.Title); err != nil {
-		http.Error(w, "Invalid title: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, apperr.Validation("invalid title: %v", err))
 		return
 	}
 
-	id, err := h.Forum.CreateDiscussion(r.Context(), request.Title, request.Content, AuthorID)
+	id, status, err := h.Forum.CreateDiscussion(r.Context(), request.Title, request.Content, request.Tags, AuthorID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"id": id})
+	writeCreatedPost(w, id, status)
 	log.Println("CreateDisc func ended")
 }
 
+// writeCreatedPost answers 201 for a published post and 202 for a post
+// held for review.
+func writeCreatedPost(w http.ResponseWriter, id, status string) {
+	code := http.StatusCreated
+	if status == models.PostHeld {
+		code = http.StatusAccepted
+	}
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"id": id, "status": status})
+}
+
+// editStatusCode answers an edit the spam filter held with 202.
+func editStatusCode(status string) int {
+	if status == models.PostHeld {
+		return http.StatusAccepted
+	}
+	return http.StatusOK
+}
+
+type CreateCommentRequest struct {
+	RelatedTo    string `json:"related_to"`
+	DiscussionID string `json:"discussionID" validate:"required"`
+	Content      string `json:"content" validate:"required,max=70"`
+}
+
 // @Summary Comment discussion
 // @Security BearerAuth
 // @Tags discussions
-// @Description You can comment a discussion
+// @Description You can comment a discussion. A comment the spam filter flags is held for moderators (202, status "held") and shows up once approved
 // @Accept  json
 // @Produce  json
-// @Param related_to query string false "related content"
-// @Param discussionID query string true "Id of element"
-// @Param content query string true "Your comment"
+// @Param input body CreateCommentRequest true "New comment"
 // @Router /discuss/comments [post]
 func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	log.Println("CreateCom func running")
-	request := struct {
-		RelatedTo    string `json:"related_to"`
-		DiscussionID string `json:"discussionID" validate:"required"`
-		Content      string `json:"content" validate:"required,max=70"`
-	}{
-		RelatedTo:    r.URL.Query().Get("related_to"),
-		DiscussionID: r.URL.Query().Get("discussionID"),
-		Content:      r.URL.Query().Get("content"),
+	var request CreateCommentRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.RelatedTo = q.Get("related_to")
+		request.DiscussionID = q.Get("discussionID")
+		request.Content = q.Get("content")
+	}) {
+		return
 	}
 	AuthorID := r.Context().Value(UserIDKey).(int)
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
 
-	id, err := h.Forum.CreateComment(r.Context(), request.RelatedTo, request.DiscussionID, request.Content, AuthorID)
+	id, status, err := h.Forum.CreateComment(r.Context(), request.RelatedTo, request.DiscussionID, request.Content, AuthorID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"id": id})
+	writeCreatedPost(w, id, status)
 	log.Println("CreateCom func ended")
 }
 
+// parseLimit reads the optional page size of paginated endpoints.
+func parseLimit(r *http.Request) (int, error) {
+	strLimit := r.URL.Query().Get("limit")
+	if strLimit == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(strLimit)
+	if err != nil || limit < 0 || limit > 100 {
+		return 0, apperr.Validation("invalid 'limit' parameter")
+	}
+	return limit, nil
+}
+
+// parseDiscussionFilter reads the tags and resolved filters shared by the
+// list endpoints.
+func parseDiscussionFilter(r *http.Request) (models.DiscussionFilter, error) {
+	filter := models.DiscussionFilter{
+		Tags: util.ParseTags(r.URL.Query().Get("tags")),
+	}
+	if strResolved := r.URL.Query().Get("resolved"); strResolved != "" {
+		resolved, err := strconv.ParseBool(strResolved)
+		if err != nil {
+			return filter, apperr.Validation("invalid 'resolved' parameter")
+		}
+		filter.Resolved = &resolved
+	}
+	return filter, nil
+}
+
 // @Summary Get all discussions
 // @Tags discussions
-// @Description Get all discussions on site
+// @Description Get all discussions on site page by page
 // @Accept  json
 // @Produce  json
+// @Param limit query int false "Number of discussions per page (default 20, max 100)"
+// @Param cursor query string false "next_cursor value from the previous page"
+// @Param sort query string false "Order of discussions" Enums(newest, most-liked, most-commented, recently-active)
+// @Param tags query string false "Comma separated tags, discussions must have all of them"
+// @Param resolved query bool false "Only resolved (true) or only open (false) discussions"
 // @Router /discussions [get]
 func (h *Handler) GetDiscussionsWithCountOfComments(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("GetDiscWithCom func running")
-	discussion, err := h.Forum.GetAllDiscussionsWithCountOfComments(r.Context())
+	filter, err := parseDiscussionFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	limit, err := parseLimit(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+	request := struct {
+		Limit  int    `json:"limit" validate:"min=0,max=100"`
+		Cursor string `json:"cursor"`
+		Sort   string `json:"sort" validate:"omitempty,oneof=newest most-liked most-commented recently-active"`
+	}{
+		Limit:  limit,
+		Cursor: r.URL.Query().Get("cursor"),
+		Sort:   r.URL.Query().Get("sort"),
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	query := models.DiscussionListQuery{
+		DiscussionFilter: filter,
+		Limit:            request.Limit,
+		Cursor:           request.Cursor,
+		Sort:             request.Sort,
+	}
+	discussion, nextCursor, err := h.Forum.GetAllDiscussionsWithCountOfComments(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 	response := map[string]interface{}{
-		"discussion": discussion,
+		"discussion":  discussion,
+		"next_cursor": nextCursor,
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -134,6 +246,8 @@ func (h *Handler) GetDiscussionsWithCountOfComments(w http.ResponseWriter, r *ht
 // @Accept  json
 // @Produce  json
 // @Param discussionName query string true "Search term"
+// @Param tags query string false "Comma separated tags, discussions must have all of them"
+// @Param resolved query bool false "Only resolved (true) or only open (false) discussions"
 // @Router /search [get]
 func (h *Handler) SearchDiscussionsByName(w http.ResponseWriter, r *http.Request) {
 	log.Println("SearchDiscByName func running")
@@ -143,12 +257,17 @@ func (h *Handler) SearchDiscussionsByName(w http.ResponseWriter, r *http.Request
 		DiscussionName: r.URL.Query().Get("discussionName"),
 	}
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
+		return
+	}
+	filter, err := parseDiscussionFilter(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
-	discussions, err := h.Forum.SearchDiscussionsByName(r.Context(), request.DiscussionName)
+	discussions, err := h.Forum.SearchDiscussionsByName(r.Context(), request.DiscussionName, filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -161,32 +280,36 @@ func (h *Handler) SearchDiscussionsByName(w http.ResponseWriter, r *http.Request
 	log.Println("SearchDiscByName func ended")
 }
 
+type VoteRequest struct {
+	ElementId string `json:"ElementId" validate:"required"`
+	VoteType  string `json:"vote" validate:"required,oneof=like dislike none" enums:"like,dislike,none"`
+}
+
 // @Summary Submit a vote
 // @Security BearerAuth
 // @Tags discussions
-// @Description Submit a vote with either "like" or "dislike"
+// @Description Submit a vote with either "like" or "dislike", or withdraw it with "none"
 // @Accept  json
 // @Produce  json
-// @Param ElementId query string true "Id of discussion or comment"
-// @Param vote query string true "The type of vote. Can be either 'like' or 'dislike'." Enums(like, dislike)
+// @Param input body VoteRequest true "Vote"
 // @Router /discuss/vote [post]
 func (h *Handler) Vote(w http.ResponseWriter, r *http.Request) {
 	AuthorID := r.Context().Value(UserIDKey).(int)
 
-	request := struct {
-		ElementId string `json:"ElementId" validate:"required"`
-		VoteType  string `json:"vote" validate:"required"`
-	}{
-		ElementId: r.URL.Query().Get("ElementId"),
-		VoteType:  r.URL.Query().Get("vote"),
+	var request VoteRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.ElementId = q.Get("ElementId")
+		request.VoteType = q.Get("vote")
+	}) {
+		return
 	}
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
 	err := h.Forum.Vote(r.Context(), AuthorID, request.ElementId, request.VoteType)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to vote: %v", err), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -209,13 +332,12 @@ func (h *Handler) GetDiscussionWithComments(w http.ResponseWriter, r *http.Reque
 		DiscussionId: r.URL.Query().Get("discussion_id"),
 	}
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
-	fmt.Println(request.DiscussionId)
 	discussion, comments, err := h.Forum.GetDiscussionWithComments(r.Context(), request.DiscussionId)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -228,133 +350,193 @@ func (h *Handler) GetDiscussionWithComments(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(response)
 }
 
+type UpdateDiscussionRequest struct {
+	DiscussionID string `json:"discussion_id" validate:"required"`
+	Content      string `json:"content" validate:"max=70"`
+}
+
 // @Summary Update discussion
 // @Security BearerAuth
 // @Tags discussions
+// @Description An edit the spam filter flags is saved and the discussion is held for moderators (202, status "held") until approved
 // @Accept  json
 // @Produce  json
-// @Param discussion_id query string true "Id of discussion"
-// @Param content query string true "New content field"
+// @Param input body UpdateDiscussionRequest true "New content of discussion"
 // @Router /discuss/discussions/edit [put]
 func (h *Handler) UpdateDiscussion(w http.ResponseWriter, r *http.Request) {
 	AuthorID := r.Context().Value(UserIDKey).(int)
-	request := struct {
-		DiscussionID string `json:"discussion_id" validate:"required"`
-		Content      string `json:"content" validate:"max=70"`
-	}{
-		DiscussionID: r.URL.Query().Get("discussion_id"),
-		Content:      r.URL.Query().Get("content"),
+	var request UpdateDiscussionRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.DiscussionID = q.Get("discussion_id")
+		request.Content = q.Get("content")
+	}) {
+		return
 	}
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
-	discussion, err := h.Forum.UpdateDiscussion(r.Context(), request.DiscussionID, request.Content, AuthorID)
+	discussion, status, err := h.Forum.UpdateDiscussion(r.Context(), request.DiscussionID, request.Content, AuthorID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	response := map[string]interface{}{
 		"Updated discussion": discussion,
+		"status":             status,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(editStatusCode(status))
 	json.NewEncoder(w).Encode(response)
 }
 
+type UpdateCommentRequest struct {
+	CommentID string `json:"comment_id" validate:"required"`
+	Content   string `json:"content" validate:"max=70"`
+}
+
 // @Summary Update comment
 // @Security BearerAuth
 // @Tags discussions
+// @Description An edit the spam filter flags is saved and the comment is held for moderators (202, status "held") until approved
 // @Accept  json
 // @Produce  json
-// @Param comment_id query string true "Id of comment"
-// @Param content query string true "New content field"
+// @Param input body UpdateCommentRequest true "New content of comment"
 // @Router /discuss/comments/edit [put]
 func (h *Handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
 	AuthorID := r.Context().Value(UserIDKey).(int)
-	request := struct {
-		CommentID string `json:"comment_id" validate:"required"`
-		Content   string `json:"content" validate:"max=70"`
-	}{
-		CommentID: r.URL.Query().Get("comment_id"),
-		Content:   r.URL.Query().Get("content"),
+	var request UpdateCommentRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.CommentID = q.Get("comment_id")
+		request.Content = q.Get("content")
+	}) {
+		return
 	}
 	if request.Content == "" {
-		http.Error(w, "Content field cant be empty", http.StatusBadRequest)
+		writeError(w, r, apperr.Validation("content field cant be empty"))
 		return
 	}
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
-	comment, err := h.Forum.UpdateComment(r.Context(), request.CommentID, request.Content, AuthorID)
+	comment, status, err := h.Forum.UpdateComment(r.Context(), request.CommentID, request.Content, AuthorID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	response := map[string]interface{}{
 		"Updated comment": comment,
+		"status":          status,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(editStatusCode(status))
+	json.NewEncoder(w).Encode(response)
+}
+
+type AcceptAnswerRequest struct {
+	DiscussionID string `json:"discussion_id" validate:"required"`
+	CommentID    string `json:"comment_id"`
+}
+
+// @Summary Accept answer
+// @Security BearerAuth
+// @Tags discussions
+// @Description Author of discussion marks a top-level comment as the accepted answer
+// @Accept  json
+// @Produce  json
+// @Param input body AcceptAnswerRequest true "Accepted comment, empty comment_id clears it"
+// @Router /discuss/discussions/accept [put]
+func (h *Handler) AcceptAnswer(w http.ResponseWriter, r *http.Request) {
+	AuthorID := r.Context().Value(UserIDKey).(int)
+	var request AcceptAnswerRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.DiscussionID = q.Get("discussion_id")
+		request.CommentID = q.Get("comment_id")
+	}) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	discussion, err := h.Forum.AcceptAnswer(r.Context(), request.DiscussionID, request.CommentID, AuthorID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"Updated discussion": discussion,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+type DeleteDiscussionRequest struct {
+	DiscussionID string `json:"discussion_id" validate:"required"`
+}
+
 // @Summary Update discussion
 // @Security BearerAuth
 // @Tags discussions
 // @Accept  json
 // @Produce  json
-// @Param discussion_id query string true "Id of discussion"
+// @Param input body DeleteDiscussionRequest true "Discussion to delete"
 // @Router /discuss/discussions/delete [delete]
 func (h *Handler) DeleteDiscussion(w http.ResponseWriter, r *http.Request) {
-	user_role := r.Context().Value(UserRoleKey).(string)
-	if user_role != models.AdministrationRole {
-		http.Error(w, "you dont have permisions to do this", http.StatusInternalServerError)
+	var request DeleteDiscussionRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.DiscussionID = q.Get("discussion_id")
+	}) {
 		return
 	}
-	request := struct {
-		DiscussionID string `json:"discussion_id" validate:"required"`
-	}{
-		DiscussionID: r.URL.Query().Get("discussion_id"),
-	}
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
-	err := h.Forum.DeleteFullDiscussion(r.Context(), request.DiscussionID)
+	userID := r.Context().Value(UserIDKey).(int)
+	err := h.Forum.DeleteFullDiscussion(r.Context(), request.DiscussionID, userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+type DeleteCommentRequest struct {
+	CommentID string `json:"comment_id" validate:"required"`
+}
+
 // @Summary Delete comment
 // @Security BearerAuth
 // @Tags discussions
 // @Accept  json
 // @Produce  json
-// @Param comment_id query string true "Id of comment"
+// @Param input body DeleteCommentRequest true "Comment to delete"
 // @Router /discuss/comments/delete [delete]
 func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	AuthorID := r.Context().Value(UserIDKey).(int)
 	UserRole := r.Context().Value(UserRoleKey).(string)
-	request := struct {
-		CommentID string `json:"comment_id" validate:"required"`
-	}{
-		CommentID: r.URL.Query().Get("comment_id"),
+	var request DeleteCommentRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.CommentID = q.Get("comment_id")
+	}) {
+		return
 	}
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
 	err := h.Forum.DeleteComment(r.Context(), request.CommentID, UserRole, AuthorID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -3,8 +3,15 @@ package handler
 import (
 	"gohelp/internal/service/auth"
 	"gohelp/internal/service/forum"
+	"gohelp/internal/service/moderation"
+	"gohelp/internal/service/notification"
+	"gohelp/internal/service/rbac"
+	"gohelp/pkg/pubsub"
+	"gohelp/pkg/ratelimit"
+	"os"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	_ "gohelp/docs"
@@ -13,39 +20,140 @@ import (
 type Handler struct {
 	Users
 	Forum
+	Moderation
+	Notifications
+	// queryInput keeps accepting the input of write endpoints from the
+	// query string. It is deprecated and can be switched off with
+	// ALLOW_QUERY_PARAMS=false.
+	queryInput bool
+	limiter    *ratelimit.Limiter
+	events     pubsub.Broker
+	// trustProxy takes the client IP from X-Forwarded-For and X-Real-IP.
+	// Set TRUST_PROXY=true only behind a proxy that sets them, clients
+	// could pick their IP otherwise.
+	trustProxy bool
 }
 
-func NewHandler(user *auth.UserService, forum *forum.ForumService) *Handler {
-	return &Handler{Users: user, Forum: forum}
+func NewHandler(user *auth.UserService, forum *forum.ForumService, moderation *moderation.ModerationService,
+	notifications *notification.NotificationService, limiter *ratelimit.Limiter, events *pubsub.Hub) *Handler {
+	return &Handler{
+		Users:         user,
+		Forum:         forum,
+		Moderation:    moderation,
+		Notifications: notifications,
+		queryInput:    os.Getenv("ALLOW_QUERY_PARAMS") != "false",
+		limiter:       limiter,
+		events:        events,
+		trustProxy:    os.Getenv("TRUST_PROXY") == "true",
+	}
 }
 
 func (h *Handler) InitRoutes() *chi.Mux {
 	r := chi.NewRouter()
+	r.Use(middleware.RequestID, requestIDHeader)
+	if h.trustProxy {
+		r.Use(middleware.RealIP)
+	}
 
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
+	r.Get("/.well-known/paseto-keys", h.GetPublicKeys)
 
 	r.Get("/discussions", h.GetDiscussionsWithCountOfComments)
 	r.Get("/search", h.SearchDiscussionsByName)
 	r.Get("/getdiscussion", h.GetDiscussionWithComments)
+	r.Get("/events", h.StreamEvents)
+	r.Route("/tags", func(r chi.Router) {
+		r.Get("/", h.GetTags)
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware, h.RequirePermission(rbac.TagManage))
+			r.Post("/", h.CreateTag)
+			r.Put("/describe", h.DescribeTag)
+			r.Put("/rename", h.RenameTag)
+			r.Post("/merge", h.MergeTag)
+		})
+	})
 	r.Route("/auth", func(r chi.Router) {
-		r.Post("/register", h.SignUp)
-		r.Post("/login", h.SignIn)
-		r.Get("/google", h.GoogleLogin)
-		r.Get("/google/callback", h.GoogleCallbackHandler)
+		authLimit := h.RateLimit(ratelimit.GroupAuth)
+		r.With(authLimit).Post("/register", h.SignUp)
+		r.With(authLimit).Post("/login", h.SignIn)
+		r.Post("/refresh", h.Refresh)
+		r.Post("/logout", h.LogoutSession)
+		r.With(h.AuthMiddleware).Post("/logout-all", h.LogoutAllSessions)
+		r.Get("/verify-email", h.VerifyUserEmail)
+		r.With(h.AuthMiddleware, authLimit).Post("/verify-email/resend", h.ResendVerificationEmail)
+		r.With(authLimit).Post("/forgot-password", h.ForgotUserPassword)
+		r.With(authLimit).Post("/reset-password", h.ResetUserPassword)
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware)
+			r.Get("/identities", h.GetIdentities)
+			r.Post("/identities/{provider}/link", h.LinkProvider)
+			r.Delete("/identities/{provider}", h.UnlinkProvider)
+		})
+		r.Get("/{provider}", h.OAuthStart)
+		r.Get("/{provider}/callback", h.OAuthCallback)
 	})
 	r.Route("/users", func(r chi.Router) {
+		r.Get("/{id}", h.GetUserProfile)
+		r.Get("/{id}/discussions", h.GetUserDiscussions)
+		r.Get("/{id}/comments", h.GetUserComments)
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware)
+			r.With(h.RequirePermission(rbac.UserBan)).Put("/actions", h.UsersActions)
+			r.With(h.RequirePermission(rbac.UserBan)).Get("/{id}/sanctions", h.GetUserSanctions)
+			r.With(h.RequirePermission(rbac.UserBan)).Post("/{id}/sanctions", h.SanctionUser)
+			r.With(h.RequirePermission(rbac.UserBan)).Post("/{id}/sanctions/lift", h.LiftUserSanctions)
+			r.With(h.RequirePermission(rbac.ContentRestore)).Post("/{id}/content/restore", h.RestoreUserContent)
+			r.With(h.RequirePermission(rbac.ReputationRecompute)).Post("/reputation/recompute", h.RecomputeReputation)
+			r.With(h.RequirePermission(rbac.RoleAssign)).Get("/roles", h.GetRoles)
+			r.With(h.RequirePermission(rbac.RoleAssign)).Put("/{id}/role", h.AssignUserRole)
+		})
+	})
+	r.Route("/reports", func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.With(h.RequireVerifiedEmail, h.RateLimit(ratelimit.GroupPost)).Post("/", h.CreateReport)
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequirePermission(rbac.ReportReview))
+			r.Get("/", h.GetReports)
+			r.Get("/{id}", h.GetReportDetails)
+			r.Post("/{id}/resolve", h.ResolveReport)
+		})
+	})
+	r.Route("/held", func(r chi.Router) {
+		r.Use(h.AuthMiddleware, h.RequirePermission(rbac.PostReview))
+		r.Get("/", h.GetHeldPosts)
+		r.Post("/{type}/{id}/approve", h.ApproveHeldPost)
+		r.Post("/{type}/{id}/reject", h.RejectHeldPost)
+	})
+	r.Route("/notifications", func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Get("/", h.GetNotifications)
+		r.Post("/{id}/read", h.MarkNotificationRead)
+		r.Post("/read-all", h.MarkAllNotificationsRead)
+		r.Get("/preferences", h.GetNotificationPreferences)
+		r.Put("/preferences", h.UpdateNotificationPreferences)
+	})
+	r.Route("/subscriptions", func(r chi.Router) {
 		r.Use(h.AuthMiddleware)
-		r.Put("/actions", h.UsersActions)
+		r.Get("/", h.GetSubscriptions)
+		r.With(h.RateLimit(ratelimit.GroupEdit)).Post("/{type}/{id}", h.Follow)
+		r.Delete("/{type}/{id}", h.Unfollow)
 	})
+	r.With(h.AuthMiddleware).Get("/feed", h.GetFeed)
 	r.Route("/discuss", func(r chi.Router) {
 		r.Use(h.AuthMiddleware)
-		r.Post("/discussions", h.CreateDiscussion)
-		r.Post("/comments", h.CreateComment)
-		r.Post("/vote", h.Vote)
-		r.Put("/discussions/edit", h.UpdateDiscussion)
-		r.Put("/comments/edit", h.UpdateComment)
-		r.Delete("/discussions/delete", h.DeleteDiscussion)
-		r.Delete("/comments/delete", h.DeleteComment)
+		postLimit, editLimit := h.RateLimit(ratelimit.GroupPost), h.RateLimit(ratelimit.GroupEdit)
+		r.With(h.RequireVerifiedEmail, postLimit).Post("/discussions", h.CreateDiscussion)
+		r.With(h.RequireVerifiedEmail, postLimit).Post("/comments", h.CreateComment)
+		r.With(h.RequireVerifiedEmail, h.RateLimit(ratelimit.GroupVote)).Post("/vote", h.Vote)
+		r.With(editLimit).Put("/discussions/edit", h.UpdateDiscussion)
+		r.With(editLimit).Put("/comments/edit", h.UpdateComment)
+		r.With(editLimit).Put("/discussions/tags", h.UpdateDiscussionTags)
+		r.With(editLimit).Put("/discussions/accept", h.AcceptAnswer)
+		r.With(h.RequirePermission(rbac.DiscussionDeleteAny)).Delete("/discussions/delete", h.DeleteDiscussion)
+		r.With(editLimit).Delete("/comments/delete", h.DeleteComment)
+		r.Get("/revisions", h.GetRevisions)
+		r.Get("/revisions/diff", h.DiffRevisions)
+		r.With(h.RequirePermission(rbac.RevisionRollback)).Put("/revisions/rollback", h.RollbackPost)
 	})
 
 	return r
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"gohelp/internal/models"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Held posts
+// @Security BearerAuth
+// @Tags moderation
+// @Description Discussions and comments the spam filter held, the oldest first, with what the filter found
+// @Produce  json
+// @Param limit query int false "Number of posts per page (default 20, max 100)"
+// @Param cursor query string false "next_cursor value from the previous page"
+// @Router /held [get]
+func (h *Handler) GetHeldPosts(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	posts, nextCursor, err := h.ListHeld(r.Context(), models.HeldListQuery{
+		Limit:  limit,
+		Cursor: r.URL.Query().Get("cursor"),
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	response := map[string]interface{}{
+		"posts":       posts,
+		"next_cursor": nextCursor,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// reviewHeldPost decodes the decision and passes it to review.
+func (h *Handler) reviewHeldPost(w http.ResponseWriter, r *http.Request, status string,
+	review func(r *http.Request, postType, postID string, moderatorID int, note string) error) {
+	moderatorID := r.Context().Value(UserIDKey).(int)
+	var request models.ReviewHeldRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	postType, postID := chi.URLParam(r, "type"), chi.URLParam(r, "id")
+	if err := review(r, postType, postID, moderatorID, request.Note); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"id": postID, "status": status})
+}
+
+// @Summary Approve held post
+// @Security BearerAuth
+// @Tags moderation
+// @Description Publish a discussion or comment the spam filter held
+// @Accept  json
+// @Produce  json
+// @Param type path string true "Post type" Enums(discussion, comment)
+// @Param id path string true "Post ID"
+// @Param input body models.ReviewHeldRequest true "Optional note"
+// @Router /held/{type}/{id}/approve [post]
+func (h *Handler) ApproveHeldPost(w http.ResponseWriter, r *http.Request) {
+	h.reviewHeldPost(w, r, models.PostPublished, func(r *http.Request, postType, postID string, moderatorID int, note string) error {
+		if err := h.ApproveHeld(r.Context(), postType, postID, moderatorID, note); err != nil {
+			return err
+		}
+		// The post is published already, the announcement is a bonus.
+		if err := h.AnnouncePost(r.Context(), postType, postID); err != nil {
+			log.Printf("failed to announce %s %s: %v", postType, postID, err)
+		}
+		return nil
+	})
+}
+
+// @Summary Reject held post
+// @Security BearerAuth
+// @Tags moderation
+// @Description Keep a discussion or comment the spam filter held hidden for good
+// @Accept  json
+// @Produce  json
+// @Param type path string true "Post type" Enums(discussion, comment)
+// @Param id path string true "Post ID"
+// @Param input body models.ReviewHeldRequest true "Optional note"
+// @Router /held/{type}/{id}/reject [post]
+func (h *Handler) RejectHeldPost(w http.ResponseWriter, r *http.Request) {
+	h.reviewHeldPost(w, r, models.PostRejected, func(r *http.Request, postType, postID string, moderatorID int, note string) error {
+		return h.RejectHeld(r.Context(), postType, postID, moderatorID, note)
+	})
+}
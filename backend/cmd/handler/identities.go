@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/pkg"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/markbates/goth"
+)
+
+// linkCookie keeps the link token while the provider redirects the browser.
+// Only the response that issues the token sets it, so linking continues in
+// the browser that asked for the link and nowhere else.
+const linkCookie = "oauth_link"
+
+func setLinkCookie(w http.ResponseWriter, token string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     linkCookie,
+		Value:    token,
+		Path:     "/auth",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// @Summary Sign in with a provider
+// @Tags users
+// @Description Redirect to an OAuth provider, the callback answers with tokens. With link the provider account is linked to the user who asked for the link instead, in the browser that asked for it
+// @Param provider path string true "Name of the provider"
+// @Param link query bool false "Continue the link started with /auth/identities/{provider}/link"
+// @Router /auth/{provider} [get]
+func (h *Handler) OAuthStart(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("link") == "true" {
+		if _, err := r.Cookie(linkCookie); err != nil {
+			writeError(w, r, apperr.Validation("no link was started in this browser, start it from your account"))
+			return
+		}
+	} else {
+		setLinkCookie(w, "", -1)
+	}
+	if err := pkg.StartOAuth(w, r, chi.URLParam(r, "provider")); err != nil {
+		writeError(w, r, providerError(err))
+	}
+}
+
+// @Summary OAuth callback
+// @Tags users
+// @Description Where the provider sends the user back to. Answers with tokens, or with the linked account when linking
+// @Produce  json
+// @Param provider path string true "Name of the provider"
+// @Router /auth/{provider}/callback [get]
+func (h *Handler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+	user, err := pkg.CompleteOAuth(w, r, provider)
+	if err != nil {
+		writeError(w, r, providerError(err))
+		return
+	}
+	identity := externalIdentity(user)
+
+	if cookie, err := r.Cookie(linkCookie); err == nil {
+		setLinkCookie(w, "", -1)
+		linked, err := h.LinkIdentity(r.Context(), cookie.Value, identity)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(linked)
+		return
+	}
+
+	tokens, err := h.OAuthLogin(r.Context(), identity)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+func providerError(err error) error {
+	if errors.Is(err, pkg.ErrUnknownProvider) {
+		return apperr.NotFound("provider not found")
+	}
+	return apperr.Unauthorized("failed to authenticate with provider")
+}
+
+func externalIdentity(user goth.User) models.ExternalIdentity {
+	return models.ExternalIdentity{
+		Provider:      user.Provider,
+		Subject:       user.UserID,
+		Email:         user.Email,
+		EmailVerified: pkg.EmailVerified(user),
+		AvatarURL:     user.AvatarURL,
+	}
+}
+
+// @Summary Linked accounts
+// @Security BearerAuth
+// @Tags users
+// @Description Provider accounts current user can sign in with and the providers that can be linked
+// @Produce  json
+// @Router /auth/identities [get]
+func (h *Handler) GetIdentities(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	identities, err := h.Identities(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	response := map[string]interface{}{
+		"identities": identities,
+		"providers":  pkg.OAuthProviders(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Link provider
+// @Security BearerAuth
+// @Tags users
+// @Description Get a link that connects an account of the provider to current user. It works once, for 10 minutes, and only in the browser that got it
+// @Produce  json
+// @Param provider path string true "Name of the provider"
+// @Router /auth/identities/{provider}/link [post]
+func (h *Handler) LinkProvider(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	provider := chi.URLParam(r, "provider")
+	if !knownProvider(provider) {
+		writeError(w, r, providerError(pkg.ErrUnknownProvider))
+		return
+	}
+	link, token, err := h.StartLink(r.Context(), userID, provider)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	setLinkCookie(w, token, 600)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"url": link})
+}
+
+// @Summary Unlink provider
+// @Security BearerAuth
+// @Tags users
+// @Description Stop signing in with the provider. The only way to sign in can't be unlinked
+// @Param provider path string true "Name of the provider"
+// @Router /auth/identities/{provider} [delete]
+func (h *Handler) UnlinkProvider(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	if err := h.UnlinkIdentity(r.Context(), userID, chi.URLParam(r, "provider")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func knownProvider(name string) bool {
+	_, err := goth.GetProvider(name)
+	return err == nil
+}
@@ -2,8 +2,12 @@ package handler
 
 import (
 	"context"
-	"gohelp/internal/service/auth"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/service/rbac"
+	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -11,31 +15,91 @@ type contextKey string
 const( 
 	UserIDKey contextKey = "user_id"
 	UserRoleKey contextKey = "user_role"
+	UserVerifiedKey contextKey = "user_verified"
 )
 
 func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Authorization header missing", http.StatusUnauthorized)
+			writeError(w, r, apperr.Unauthorized("authorization header missing"))
 			return
 		}
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == authHeader {
-			http.Error(w, "Invalid token format", http.StatusUnauthorized)
+			writeError(w, r, apperr.Unauthorized("invalid token format"))
 			return
 		}
 
-		payload, err := auth.ValidatePasetoToken(token)
+		payload, err := h.Users.Authenticate(r.Context(), token)
 		if err != nil {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			writeError(w, r, err)
 			return
 		}
 
 		ctx := context.WithValue(r.Context(), UserRoleKey, payload.Role)
 		ctx = context.WithValue(ctx, UserIDKey, payload.UserID)
+		ctx = context.WithValue(ctx, UserVerifiedKey, payload.EmailVerified)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// RequireVerifiedEmail lets only users with a verified email through. It
+// goes after AuthMiddleware.
+func (h *Handler) RequireVerifiedEmail(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if verified, _ := r.Context().Value(UserVerifiedKey).(bool); !verified {
+			writeError(w, r, apperr.Forbidden("verify your email to do this"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission lets only users whose role grants the permission
+// through. It goes after AuthMiddleware.
+func (h *Handler) RequirePermission(permission rbac.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if role, _ := r.Context().Value(UserRoleKey).(string); !rbac.Can(role, permission) {
+				writeError(w, r, errForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP is the address of the client without the port. RealIP leaves
+// no port in RemoteAddr.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimit takes every request from the bucket of the group. Requests are
+// counted per user after AuthMiddleware and per client IP before it. The
+// requests go on when the store fails, the limits are not worth an outage.
+func (h *Handler) RateLimit(group string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := "ip:" + clientIP(r)
+			if userID, ok := r.Context().Value(UserIDKey).(int); ok {
+				client = "user:" + strconv.Itoa(userID)
+			}
+			wait, err := h.limiter.Allow(r.Context(), group, client)
+			if err != nil {
+				log.Printf("rate limit of %s failed: %v", group, err)
+			} else if wait > 0 {
+				writeError(w, r, apperr.TooManyRequests(wait, "too many requests, try again later"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
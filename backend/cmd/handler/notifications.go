@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Notifications interface {
+	List(ctx context.Context, query models.NotificationListQuery) ([]models.Notification, int, string, error)
+	MarkRead(ctx context.Context, userID int, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID int) (int64, error)
+	Preferences(ctx context.Context, userID int) (models.NotificationPreferences, error)
+	SetPreferences(ctx context.Context, userID int, preferences models.NotificationPreferences) (models.NotificationPreferences, error)
+}
+
+// @Summary Notifications
+// @Security BearerAuth
+// @Tags notifications
+// @Description Notifications of the user, the latest first, with the number of unread ones
+// @Produce  json
+// @Param unread query bool false "Only unread notifications"
+// @Param limit query int false "Number of notifications per page (default 20, max 100)"
+// @Param cursor query string false "next_cursor value from the previous page"
+// @Router /notifications [get]
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	limit, err := parseLimit(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	query := models.NotificationListQuery{
+		UserID: userID,
+		Limit:  limit,
+		Cursor: r.URL.Query().Get("cursor"),
+	}
+	if strUnread := r.URL.Query().Get("unread"); strUnread != "" {
+		if query.UnreadOnly, err = strconv.ParseBool(strUnread); err != nil {
+			writeError(w, r, apperr.Validation("invalid 'unread' parameter"))
+			return
+		}
+	}
+	notifications, unread, nextCursor, err := h.Notifications.List(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	response := map[string]interface{}{
+		"notifications": notifications,
+		"unread_count":  unread,
+		"next_cursor":   nextCursor,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Mark notification read
+// @Security BearerAuth
+// @Tags notifications
+// @Param id path int true "Notification ID"
+// @Router /notifications/{id}/read [post]
+func (h *Handler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	notificationID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, r, apperr.Validation("invalid notification id"))
+		return
+	}
+	if err = h.Notifications.MarkRead(r.Context(), userID, notificationID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Mark all notifications read
+// @Security BearerAuth
+// @Tags notifications
+// @Produce  json
+// @Router /notifications/read-all [post]
+func (h *Handler) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	marked, err := h.Notifications.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"marked": marked})
+}
+
+// @Summary Notification preferences
+// @Security BearerAuth
+// @Tags notifications
+// @Description Every notification type with whether the user receives it
+// @Produce  json
+// @Router /notifications/preferences [get]
+func (h *Handler) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	preferences, err := h.Notifications.Preferences(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preferences)
+}
+
+// @Summary Update notification preferences
+// @Security BearerAuth
+// @Tags notifications
+// @Description Switch notification types on or off, types left out keep their setting. Types are reply, mention, answer_accepted, vote and activity
+// @Accept  json
+// @Produce  json
+// @Param input body models.NotificationPreferences true "Types to switch, for example {\"vote\": false}"
+// @Router /notifications/preferences [put]
+func (h *Handler) UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	var request models.NotificationPreferences
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	preferences, err := h.Notifications.SetPreferences(r.Context(), userID, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preferences)
+}
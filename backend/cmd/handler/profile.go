@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"gohelp/internal/service/apperr"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Get user profile
+// @Tags users
+// @Description Public profile of user
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Id of user"
+// @Router /users/{id} [get]
+func (h *Handler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, apperr.Validation("invalid 'id' parameter"))
+		return
+	}
+	profile, err := h.Forum.GetPublicProfile(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"user": profile,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Get discussions of user
+// @Tags users
+// @Description Discussions started by user, newest first
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Id of user"
+// @Param limit query int false "Number of discussions per page (default 20, max 100)"
+// @Param cursor query string false "next_cursor value from the previous page"
+// @Router /users/{id}/discussions [get]
+func (h *Handler) GetUserDiscussions(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, apperr.Validation("invalid 'id' parameter"))
+		return
+	}
+	limit, err := parseLimit(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	discussions, nextCursor, err := h.Forum.GetUserDiscussions(r.Context(), userID, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"discussions": discussions,
+		"next_cursor": nextCursor,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Get comments of user
+// @Tags users
+// @Description Comments written by user, newest first
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Id of user"
+// @Param limit query int false "Number of comments per page (default 20, max 100)"
+// @Param cursor query string false "next_cursor value from the previous page"
+// @Router /users/{id}/comments [get]
+func (h *Handler) GetUserComments(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, apperr.Validation("invalid 'id' parameter"))
+		return
+	}
+	limit, err := parseLimit(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	comments, nextCursor, err := h.Forum.GetUserComments(r.Context(), userID, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"comments":    comments,
+		"next_cursor": nextCursor,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
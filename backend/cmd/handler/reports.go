@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/service/rbac"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Moderation interface {
+	FileReport(ctx context.Context, reporterID int, request models.CreateReportRequest) (*models.Report, error)
+	ListReports(ctx context.Context, query models.ReportListQuery) ([]models.Report, string, error)
+	GetReport(ctx context.Context, reportID int64) (*models.Report, error)
+	PrepareResolution(ctx context.Context, reportID int64, request models.ResolveReportRequest) (*models.Report, error)
+	RecordResolution(ctx context.Context, resolution models.ReportResolution) (*models.ReportResolution, error)
+	ReleaseResolution(ctx context.Context, reportID int64)
+	ListHeld(ctx context.Context, query models.HeldListQuery) ([]models.HeldPost, string, error)
+	ApproveHeld(ctx context.Context, postType, postID string, moderatorID int, note string) error
+	RejectHeld(ctx context.Context, postType, postID string, moderatorID int, note string) error
+	SanctionUser(ctx context.Context, actorID int, actorRole string, userID int, request models.SanctionRequest) (*models.Sanction, error)
+	LiftUserSanctions(ctx context.Context, actorID int, actorRole string, userID int, request models.LiftSanctionRequest) error
+	RestoreUserContent(ctx context.Context, userID int) (int, error)
+}
+
+func reportIDParam(r *http.Request) (int64, error) {
+	reportID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return 0, apperr.Validation("invalid report id")
+	}
+	return reportID, nil
+}
+
+// @Summary Report
+// @Security BearerAuth
+// @Tags reports
+// @Description Flag a discussion, comment or user for moderators. Every user can flag the same target once
+// @Accept  json
+// @Produce  json
+// @Param input body models.CreateReportRequest true "What is reported and why"
+// @Router /reports [post]
+func (h *Handler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	var request models.CreateReportRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	report, err := h.FileReport(r.Context(), userID, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int64{"report_id": report.ID})
+}
+
+// @Summary Moderation queue
+// @Security BearerAuth
+// @Tags reports
+// @Description Open reports, the most severe and most reported first, or resolved reports, the latest first
+// @Produce  json
+// @Param status query string false "Status of reports" Enums(open, resolving, resolved)
+// @Param limit query int false "Number of reports per page (default 20, max 100)"
+// @Param cursor query string false "next_cursor value from the previous page"
+// @Router /reports [get]
+func (h *Handler) GetReports(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	reports, nextCursor, err := h.ListReports(r.Context(), models.ReportListQuery{
+		Status: r.URL.Query().Get("status"),
+		Limit:  limit,
+		Cursor: r.URL.Query().Get("cursor"),
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	response := map[string]interface{}{
+		"reports":     reports,
+		"next_cursor": nextCursor,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Report details
+// @Security BearerAuth
+// @Tags reports
+// @Description Report with every flag and the audit log of its resolutions
+// @Produce  json
+// @Param id path int true "Report ID"
+// @Router /reports/{id} [get]
+func (h *Handler) GetReportDetails(w http.ResponseWriter, r *http.Request) {
+	reportID, err := reportIDParam(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	report, err := h.Moderation.GetReport(r.Context(), reportID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// @Summary Resolve report
+// @Security BearerAuth
+// @Tags reports
+// @Description Dismiss the report, delete the reported content or suspend its author. The resolution is kept in the audit log. A report another moderator is resolving answers 409
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Report ID"
+// @Param input body models.ResolveReportRequest true "Action, note and the suspension for the suspend action"
+// @Router /reports/{id}/resolve [post]
+func (h *Handler) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	moderatorID := r.Context().Value(UserIDKey).(int)
+	moderatorRole := r.Context().Value(UserRoleKey).(string)
+	reportID, err := reportIDParam(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var request models.ResolveReportRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	report, err := h.PrepareResolution(r.Context(), reportID, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// The report stays claimed only if it got resolved.
+	resolved := false
+	defer func() {
+		if !resolved {
+			h.ReleaseResolution(context.WithoutCancel(r.Context()), report.ID)
+		}
+	}()
+
+	resolution := models.ReportResolution{
+		ReportID:    report.ID,
+		ModeratorID: &moderatorID,
+		Action:      request.Action,
+		Note:        request.Note,
+	}
+	switch request.Action {
+	case models.ResolveDelete:
+		if report.TargetType == models.PostDiscussion {
+			if !rbac.Can(moderatorRole, rbac.DiscussionDeleteAny) {
+				writeError(w, r, errForbidden)
+				return
+			}
+			err = h.Forum.DeleteFullDiscussion(r.Context(), report.TargetID, moderatorID)
+		} else {
+			err = h.Forum.DeleteComment(r.Context(), report.TargetID, moderatorRole, moderatorID)
+		}
+	case models.ResolveSuspend:
+		if !rbac.Can(moderatorRole, rbac.UserBan) {
+			writeError(w, r, errForbidden)
+			return
+		}
+		var sanction *models.Sanction
+		sanction, err = h.Moderation.SanctionUser(r.Context(), moderatorID, moderatorRole, report.AuthorID, *request.Suspension)
+		if sanction != nil {
+			resolution.SanctionID = &sanction.ID
+		}
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	recorded, err := h.RecordResolution(r.Context(), resolution)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	resolved = true
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recorded)
+}
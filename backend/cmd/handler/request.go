@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"encoding/json"
+	"gohelp/internal/service/apperr"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const maxBodySize = 1 << 20
+
+// decodeRequest fills dst from the JSON body of a write request. Clients
+// that still send their input in the query string are served by fromQuery
+// while queryInput is enabled, and are told that this form is deprecated.
+// Endpoints that never took query input pass a nil fromQuery.
+func (h *Handler) decodeRequest(w http.ResponseWriter, r *http.Request, dst interface{}, fromQuery func(q url.Values)) bool {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+			writeError(w, r, apperr.Validation("invalid JSON body: %v", err))
+			return false
+		}
+		return true
+	}
+	if !h.queryInput || fromQuery == nil {
+		writeError(w, r, apperr.New(apperr.ErrUnsupportedMediaType, "request body must be application/json"))
+		return false
+	}
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Warning", `299 - "query string input is deprecated, send a JSON body"`)
+	fromQuery(r.URL.Query())
+	return true
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"gohelp/internal/service/apperr"
+	"net/http"
+	"strconv"
+)
+
+// parseVersion reads an optional version number from the query string.
+func parseVersion(r *http.Request, name string) (int, error) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(str)
+	if err != nil || version < 1 {
+		return 0, apperr.Validation("invalid '%s' parameter", name)
+	}
+	return version, nil
+}
+
+// @Summary Get revisions
+// @Security BearerAuth
+// @Tags revisions
+// @Description Edit history of discussion or comment, available to its author and moderators
+// @Accept  json
+// @Produce  json
+// @Param post_type query string true "Type of post" Enums(discussion, comment)
+// @Param post_id query string true "Id of post"
+// @Router /discuss/revisions [get]
+func (h *Handler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	userRole := r.Context().Value(UserRoleKey).(string)
+	request := struct {
+		PostType string `json:"post_type" validate:"required,oneof=discussion comment"`
+		PostID   string `json:"post_id" validate:"required"`
+	}{
+		PostType: r.URL.Query().Get("post_type"),
+		PostID:   r.URL.Query().Get("post_id"),
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	revisions, current, err := h.Forum.GetRevisions(r.Context(), request.PostType, request.PostID, userID, userRole)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"revisions":       revisions,
+		"current_version": current,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Diff revisions
+// @Security BearerAuth
+// @Tags revisions
+// @Description Word by word difference between two versions of discussion or comment
+// @Accept  json
+// @Produce  json
+// @Param post_type query string true "Type of post" Enums(discussion, comment)
+// @Param post_id query string true "Id of post"
+// @Param from query int true "Version to compare from"
+// @Param to query int false "Version to compare to, current version by default"
+// @Router /discuss/revisions/diff [get]
+func (h *Handler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	userRole := r.Context().Value(UserRoleKey).(string)
+	from, err := parseVersion(r, "from")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	to, err := parseVersion(r, "to")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	request := struct {
+		PostType string `json:"post_type" validate:"required,oneof=discussion comment"`
+		PostID   string `json:"post_id" validate:"required"`
+		From     int    `json:"from" validate:"required"`
+	}{
+		PostType: r.URL.Query().Get("post_type"),
+		PostID:   r.URL.Query().Get("post_id"),
+		From:     from,
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	diff, err := h.Forum.DiffRevisions(r.Context(), request.PostType, request.PostID, request.From, to, userID, userRole)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"diff": diff,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+type RollbackPostRequest struct {
+	PostType string `json:"post_type" validate:"required,oneof=discussion comment" enums:"discussion,comment"`
+	PostID   string `json:"post_id" validate:"required"`
+	Version  int    `json:"version" validate:"required,min=1"`
+}
+
+// @Summary Roll back post
+// @Security BearerAuth
+// @Tags revisions
+// @Description Moderator restores content of an earlier version, the rollback is saved as a new revision. A rollback the spam filter flags holds the post for review (202, status "held")
+// @Accept  json
+// @Produce  json
+// @Param input body RollbackPostRequest true "Post and version to restore"
+// @Router /discuss/revisions/rollback [put]
+func (h *Handler) RollbackPost(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	var request RollbackPostRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	status, err := h.Forum.RollbackPost(r.Context(), request.PostType, request.PostID, request.Version, userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(editStatusCode(status))
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
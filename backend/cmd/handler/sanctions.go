@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func userIDParam(r *http.Request) (int, error) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, apperr.Validation("invalid user id")
+	}
+	return userID, nil
+}
+
+// @Summary Sanction history
+// @Security BearerAuth
+// @Tags users
+// @Description Every sanction of the user, the latest first
+// @Produce  json
+// @Param id path int true "User ID"
+// @Router /users/{id}/sanctions [get]
+func (h *Handler) GetUserSanctions(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDParam(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	sanctions, err := h.Sanctions(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"sanctions": sanctions})
+}
+
+// @Summary Suspend user
+// @Security BearerAuth
+// @Tags users
+// @Description Suspend the user until expires_at, or for good without it. The content of the user stays unless wipe_content is set
+// @Accept  json
+// @Produce  json
+// @Param id path int true "User ID"
+// @Param input body models.SanctionRequest true "Reason, end and whether to wipe content"
+// @Router /users/{id}/sanctions [post]
+func (h *Handler) SanctionUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDParam(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var request models.SanctionRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	actorID := r.Context().Value(UserIDKey).(int)
+	actorRole := r.Context().Value(UserRoleKey).(string)
+	sanction, err := h.Moderation.SanctionUser(r.Context(), actorID, actorRole, userID, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sanction)
+}
+
+// @Summary Lift sanctions
+// @Security BearerAuth
+// @Tags users
+// @Description Lift every active sanction of the user. With restore_content the content wiped by earlier sanctions comes back
+// @Accept  json
+// @Param id path int true "User ID"
+// @Param input body models.LiftSanctionRequest true "Reason and whether to restore content"
+// @Router /users/{id}/sanctions/lift [post]
+func (h *Handler) LiftUserSanctions(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDParam(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var request models.LiftSanctionRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	actorID := r.Context().Value(UserIDKey).(int)
+	actorRole := r.Context().Value(UserRoleKey).(string)
+	if err = h.Moderation.LiftUserSanctions(r.Context(), actorID, actorRole, userID, request); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Restore content of user
+// @Security BearerAuth
+// @Tags users
+// @Description Bring back the content hidden by sanctions of the user that were lifted or have expired. What the user or moderators deleted stays deleted
+// @Produce  json
+// @Param id path int true "User ID"
+// @Router /users/{id}/content/restore [post]
+func (h *Handler) RestoreUserContent(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDParam(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	restored, err := h.Moderation.RestoreUserContent(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"restored_sanctions": restored})
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Subscriptions
+// @Security BearerAuth
+// @Tags subscriptions
+// @Description Discussions, tags and users the user follows, the latest first
+// @Produce  json
+// @Router /subscriptions [get]
+func (h *Handler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	subscriptions, err := h.Forum.GetSubscriptions(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"subscriptions": subscriptions})
+}
+
+// @Summary Follow
+// @Security BearerAuth
+// @Tags subscriptions
+// @Description Follow a discussion, a tag or a user to get notified about new posts there and see them in the feed. Following again changes nothing
+// @Param type path string true "What to follow: discussion, tag or user"
+// @Param id path string true "Discussion ID, tag name or user ID"
+// @Router /subscriptions/{type}/{id} [post]
+func (h *Handler) Follow(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	if err := h.Forum.Follow(r.Context(), userID, chi.URLParam(r, "type"), chi.URLParam(r, "id")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Unfollow
+// @Security BearerAuth
+// @Tags subscriptions
+// @Param type path string true "What to unfollow: discussion, tag or user"
+// @Param id path string true "Discussion ID, tag name or user ID"
+// @Router /subscriptions/{type}/{id} [delete]
+func (h *Handler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	if err := h.Forum.Unfollow(r.Context(), userID, chi.URLParam(r, "type"), chi.URLParam(r, "id")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Feed
+// @Security BearerAuth
+// @Tags subscriptions
+// @Description New discussions with a followed tag or by a followed user, and new comments in a followed discussion or by a followed user, newest first. Posts of the user are left out
+// @Produce  json
+// @Param limit query int false "Number of posts per page (default 20, max 100)"
+// @Param cursor query string false "next_cursor value from the previous page"
+// @Router /feed [get]
+func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	limit, err := parseLimit(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	items, nextCursor, err := h.Forum.GetFeed(r.Context(), userID, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	response := map[string]interface{}{
+		"items":       items,
+		"next_cursor": nextCursor,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
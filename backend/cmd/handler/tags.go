@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"gohelp/internal/service/apperr"
+	"gohelp/util"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// @Summary Get tags
+// @Tags tags
+// @Description Get tag catalog with the number of discussions using every tag
+// @Accept  json
+// @Produce  json
+// @Router /tags [get]
+func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.Forum.GetTags(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"tags": tags,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+type CreateTagRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description" validate:"max=200"`
+}
+
+// @Summary Create tag
+// @Security BearerAuth
+// @Tags tags
+// @Description Add new tag to the catalog
+// @Accept  json
+// @Produce  json
+// @Param input body CreateTagRequest true "New tag"
+// @Router /tags [post]
+func (h *Handler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var request CreateTagRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.Name = q.Get("name")
+		request.Description = q.Get("description")
+	}) {
+		return
+	}
+	request.Name = strings.ToLower(strings.TrimSpace(request.Name))
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	if err := util.ValidateTag(request.Name); err != nil {
+		writeError(w, r, apperr.Validation("invalid tag: %v", err))
+		return
+	}
+	err := h.Forum.CreateTag(r.Context(), request.Name, request.Description)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+type DescribeTagRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description" validate:"required,max=200"`
+}
+
+// @Summary Describe tag
+// @Security BearerAuth
+// @Tags tags
+// @Description Change description of tag
+// @Accept  json
+// @Produce  json
+// @Param input body DescribeTagRequest true "New description of tag"
+// @Router /tags/describe [put]
+func (h *Handler) DescribeTag(w http.ResponseWriter, r *http.Request) {
+	var request DescribeTagRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.Name = q.Get("name")
+		request.Description = q.Get("description")
+	}) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	err := h.Forum.DescribeTag(r.Context(), request.Name, request.Description)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type RenameTagRequest struct {
+	Name    string `json:"name" validate:"required"`
+	NewName string `json:"new_name" validate:"required"`
+}
+
+// @Summary Rename tag
+// @Security BearerAuth
+// @Tags tags
+// @Description Rename tag in the catalog and on every discussion
+// @Accept  json
+// @Produce  json
+// @Param input body RenameTagRequest true "Current and new name of tag"
+// @Router /tags/rename [put]
+func (h *Handler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	var request RenameTagRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.Name = q.Get("name")
+		request.NewName = q.Get("new_name")
+	}) {
+		return
+	}
+	request.NewName = strings.ToLower(strings.TrimSpace(request.NewName))
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	err := h.Forum.RenameTag(r.Context(), request.Name, request.NewName)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type MergeTagRequest struct {
+	Source string `json:"source" validate:"required"`
+	Target string `json:"target" validate:"required"`
+}
+
+// @Summary Merge tags
+// @Security BearerAuth
+// @Tags tags
+// @Description Replace source tag with target tag on every discussion and remove source from the catalog
+// @Accept  json
+// @Produce  json
+// @Param input body MergeTagRequest true "Source tag is merged into target tag"
+// @Router /tags/merge [post]
+func (h *Handler) MergeTag(w http.ResponseWriter, r *http.Request) {
+	var request MergeTagRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.Source = q.Get("source")
+		request.Target = q.Get("target")
+	}) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	err := h.Forum.MergeTag(r.Context(), request.Source, request.Target)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type UpdateDiscussionTagsRequest struct {
+	DiscussionID string   `json:"discussion_id" validate:"required"`
+	Tags         []string `json:"tags" validate:"max=5"`
+}
+
+// @Summary Update tags of discussion
+// @Security BearerAuth
+// @Tags discussions
+// @Accept  json
+// @Produce  json
+// @Param input body UpdateDiscussionTagsRequest true "New tags of discussion, empty list clears them"
+// @Router /discuss/discussions/tags [put]
+func (h *Handler) UpdateDiscussionTags(w http.ResponseWriter, r *http.Request) {
+	AuthorID := r.Context().Value(UserIDKey).(int)
+	UserRole := r.Context().Value(UserRoleKey).(string)
+	var request UpdateDiscussionTagsRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.DiscussionID = q.Get("discussion_id")
+		request.Tags = util.ParseTags(q.Get("tags"))
+	}) {
+		return
+	}
+	request.Tags = util.NormalizeTags(request.Tags)
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	discussion, err := h.Forum.UpdateDiscussionTags(r.Context(), request.DiscussionID, request.Tags, AuthorID, UserRole)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"Updated discussion": discussion,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
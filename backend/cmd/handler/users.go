@@ -5,19 +5,36 @@ import (
 	"encoding/json"
 	"fmt"
 	"gohelp/internal/models"
-	"gohelp/pkg"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/service/auth"
+	"gohelp/internal/service/rbac"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 
-	"github.com/markbates/goth"
+	"github.com/go-chi/chi/v5"
 )
 
 type Users interface {
 	RegisterUser(ctx context.Context, user models.SignUp) error
-	LoginUser(ctx context.Context, email, password string) (string, error)
-	UsersActions(ctx context.Context, userID int, action string) (*models.User, error)
-	GoogleAuth(ctx context.Context, user goth.User) (string, error)
+	LoginUser(ctx context.Context, email, password string) (*models.TokenPair, error)
+	Sanctions(ctx context.Context, userID int) ([]models.Sanction, error)
+	AssignRole(ctx context.Context, actorID, userID int, role string) (*models.User, error)
+	OAuthLogin(ctx context.Context, identity models.ExternalIdentity) (*models.TokenPair, error)
+	StartLink(ctx context.Context, userID int, provider string) (string, string, error)
+	LinkIdentity(ctx context.Context, linkToken string, identity models.ExternalIdentity) (*models.UserIdentity, error)
+	Identities(ctx context.Context, userID int) ([]models.UserIdentity, error)
+	UnlinkIdentity(ctx context.Context, userID int, provider string) error
+	RefreshSession(ctx context.Context, refreshToken string) (*models.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, userID int) error
+	Authenticate(ctx context.Context, accessToken string) (*auth.TokenPayload, error)
+	PublicKeys() []auth.PublicKey
+	ResendVerification(ctx context.Context, userID int) error
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
 }
 
 // @Summary SignUp
@@ -25,25 +42,25 @@ type Users interface {
 // @Description create account
 // @Accept  json
 // @Produce  json
-// @Param username query string true "your username"
-// @Param password query string true "your password"
-// @Param email query string true "your email"
+// @Param input body models.SignUp true "New account"
 // @Router /auth/register [post]
 func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) {
 	log.Println("signUP func running")
-	input := models.SignUp{
-		Username: r.URL.Query().Get("username"),
-		Email:    r.URL.Query().Get("email"),
-		Password: r.URL.Query().Get("password"),
+	var input models.SignUp
+	if !h.decodeRequest(w, r, &input, func(q url.Values) {
+		input.Username = q.Get("username")
+		input.Email = q.Get("email")
+		input.Password = q.Get("password")
+	}) {
+		return
 	}
-	fmt.Println(input)
 	if err := validate.Struct(input); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
 	err := h.RegisterUser(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -56,111 +73,333 @@ const (
 	classicMethod = "classic"
 )
 
+type SignInRequest struct {
+	AuthMethod string `json:"auth_method" enums:"classic,google"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+}
+
 // @Summary SignIn
 // @Tags users
-// @Description create account
+// @Description Log in. Failed logins in a row lock the account for growing periods, answered with 429 and Retry-After
 // @Accept  json
 // @Produce  json
-// @Param auth_method query string true "Authorization method" Enums(classic, google)
-// @Param email query string false "your email"
-// @Param password query string false "your password"
+// @Param input body SignInRequest true "Authorization method and credentials for the classic one"
 // @Router /auth/login [post]
 func (h *Handler) SignIn(w http.ResponseWriter, r *http.Request) {
-	if authMethod := r.URL.Query().Get("auth_method"); authMethod != classicMethod {
+	var request SignInRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.AuthMethod = q.Get("auth_method")
+		request.Email = q.Get("email")
+		request.Password = q.Get("password")
+	}) {
+		return
+	}
+	if authMethod := request.AuthMethod; authMethod != classicMethod {
 		response := fmt.Sprintf("For this action, please follow this link: http://localhost:8080/auth/%v", authMethod)
 		json.NewEncoder(w).Encode(response)
 		return
 	}
 	log.Println("signIn func running")
 	credentials := models.LoginRequest{
-		Email:    r.URL.Query().Get("email"),
-		Password: r.URL.Query().Get("password"),
+		Email:    request.Email,
+		Password: request.Password,
 	}
 	if err := validate.Struct(credentials); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
-	token, err := h.LoginUser(r.Context(), credentials.Email, credentials.Password)
+	tokens, err := h.LoginUser(r.Context(), credentials.Email, credentials.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(token)
+	json.NewEncoder(w).Encode(tokens)
 	log.Println("signIn func ended")
 }
 
-func (h *Handler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
-	pkg.StartGoogleOAuth(w, r)
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
-func (h *Handler) GoogleCallbackHandler(w http.ResponseWriter, r *http.Request) {
-	user, err := pkg.CompleteGoogleOAuth(w, r)
+// @Summary Refresh session
+// @Tags users
+// @Description Exchange refresh token for a new access token and a new refresh token, the old refresh token stops working
+// @Accept  json
+// @Produce  json
+// @Param input body RefreshTokenRequest true "Refresh token"
+// @Router /auth/refresh [post]
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var request RefreshTokenRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	tokens, err := h.RefreshSession(r.Context(), request.RefreshToken)
 	if err != nil {
-		http.Error(w, "Failed to authenticate with Google", http.StatusUnauthorized)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// @Summary Logout
+// @Tags users
+// @Description End the session of refresh token
+// @Accept  json
+// @Produce  json
+// @Param input body RefreshTokenRequest true "Refresh token of session"
+// @Router /auth/logout [post]
+func (h *Handler) LogoutSession(w http.ResponseWriter, r *http.Request) {
+	var request RefreshTokenRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	if err := h.Logout(r.Context(), request.RefreshToken); err != nil {
+		writeError(w, r, err)
 		return
 	}
-	// fmt.Println("Email: " + user.Email +
-	// 	"\n Name: " + user.Name +
-	// 	"\n FirstName: " + user.FirstName +
-	// 	"\n LastName: " + user.LastName +
-	// 	"\n Description: " + user.Description +
-	// 	"\n UserID: " + user.UserID +
-	// 	"\n AvatarURL: " + user.AvatarURL +
-	// 	"\n Location: " + user.Location +
-	// 	"\n AccessToken: " + user.AccessToken)
-	token, err := h.GoogleAuth(r.Context(), user)
-	if err != nil{
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Logout everywhere
+// @Security BearerAuth
+// @Tags users
+// @Description End all sessions of current user and revoke their access tokens
+// @Accept  json
+// @Produce  json
+// @Router /auth/logout-all [post]
+func (h *Handler) LogoutAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	if err := h.LogoutAll(r.Context(), userID); err != nil {
+		writeError(w, r, err)
 		return
 	}
-	json.NewEncoder(w).Encode(token)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type UsersActionsRequest struct {
+	UserID int    `json:"user_id" validate:"required"`
+	Action string `json:"action" validate:"required,oneof=ban unban" enums:"ban,unban"`
+	Reason string `json:"reason" validate:"max=500"`
 }
 
 // @Summary Change status of user
 // @Security BearerAuth
 // @Tags users
+// @Description Ban for good and wipe the content, or lift every sanction. Deprecated, use /users/{id}/sanctions
 // @Accept  json
 // @Produce  json
-// @Param user_id query string true "Id of User"
-// @Param action query string true "The type of action. Can be either 'ban' or 'unban'." Enums(ban, unban)
+// @Param input body UsersActionsRequest true "User and action"
 // @Router /users/actions [put]
 func (h *Handler) UsersActions(w http.ResponseWriter, r *http.Request) {
-	UserRole := r.Context().Value(UserRoleKey).(string)
-	if UserRole != models.AdministrationRole {
-		http.Error(w, "You dont have permisions to do this", http.StatusUnauthorized)
+	var request UsersActionsRequest
+	if !h.decodeRequest(w, r, &request, func(q url.Values) {
+		request.UserID, _ = strconv.Atoi(q.Get("user_id"))
+		request.Action = q.Get("action")
+		request.Reason = q.Get("reason")
+	}) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	actorID := r.Context().Value(UserIDKey).(int)
+	actorRole := r.Context().Value(UserRoleKey).(string)
+	var err error
+	if request.Action == "ban" {
+		reason := request.Reason
+		if reason == "" {
+			reason = "banned"
+		}
+		_, err = h.Moderation.SanctionUser(r.Context(), actorID, actorRole, request.UserID, models.SanctionRequest{Reason: reason, WipeContent: true})
+	} else {
+		err = h.Moderation.LiftUserSanctions(r.Context(), actorID, actorRole, request.UserID, models.LiftSanctionRequest{Reason: request.Reason})
+	}
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
-	strID := r.URL.Query().Get("user_id")
-	id, err := strconv.Atoi(strID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode("operation is completed")
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// @Summary Assign role
+// @Security BearerAuth
+// @Tags users
+// @Description Administrator changes the role of another user, the new role applies to the next request of the user
+// @Accept  json
+// @Produce  json
+// @Param id path int true "User ID"
+// @Param input body AssignRoleRequest true "New role"
+// @Router /users/{id}/role [put]
+func (h *Handler) AssignUserRole(w http.ResponseWriter, r *http.Request) {
+	actorID := r.Context().Value(UserIDKey).(int)
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
-		http.Error(w, "Invalid 'id' parameter", http.StatusBadRequest)
+		writeError(w, r, apperr.Validation("invalid user id"))
 		return
 	}
-	request := struct {
-		UserID int    `json:"user_id" validate:"required"`
-		Action string `json:"action" validate:"required"`
-	}{
-		UserID: id,
-		Action: r.URL.Query().Get("action"),
+	var request AssignRoleRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
 	}
 	if err := validate.Struct(request); err != nil {
-		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, validationError(err))
 		return
 	}
-	_, err = h.Users.UsersActions(r.Context(), request.UserID, request.Action)
+	user, err := h.AssignRole(r.Context(), actorID, userID, request.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
-	if request.Action == "ban" {
-		err = h.Forum.DeleteFullHistory(r.Context(), request.UserID)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"user_id": user.ID, "role": user.Role})
+}
+
+// @Summary Roles
+// @Security BearerAuth
+// @Tags users
+// @Description Roles from the least to the most powerful with the permissions they grant
+// @Produce  json
+// @Router /users/roles [get]
+func (h *Handler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"roles": rbac.Roles()})
+}
+
+// @Summary Recompute reputation
+// @Security BearerAuth
+// @Tags users
+// @Description Rebuild reputation of every user from votes and accepted answers
+// @Accept  json
+// @Produce  json
+// @Router /users/reputation/recompute [post]
+func (h *Handler) RecomputeReputation(w http.ResponseWriter, r *http.Request) {
+	err := h.Forum.RecomputeReputation(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode("operation is completed")
 }
+
+// @Summary Public keys of tokens
+// @Tags users
+// @Description Ed25519 keys that verify v2.public access tokens, the key of a token is named by the kid in its footer
+// @Produce  json
+// @Router /.well-known/paseto-keys [get]
+func (h *Handler) GetPublicKeys(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"keys": h.PublicKeys(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Verify email
+// @Tags users
+// @Description Confirm email with the link sent on sign up
+// @Produce  json
+// @Param token query string true "Token from the email"
+// @Router /auth/verify-email [get]
+func (h *Handler) VerifyUserEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		writeError(w, r, apperr.Validation("token is required"))
+		return
+	}
+	if err := h.VerifyEmail(r.Context(), token); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "email verified"})
+}
+
+// @Summary Resend verification email
+// @Security BearerAuth
+// @Tags users
+// @Description Send a new verification link to the email of current user
+// @Produce  json
+// @Router /auth/verify-email/resend [post]
+func (h *Handler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(UserIDKey).(int)
+	if err := h.ResendVerification(r.Context(), userID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// @Summary Forgot password
+// @Tags users
+// @Description Send a password reset link, the answer is the same whether the account exists or not
+// @Accept  json
+// @Produce  json
+// @Param input body models.ForgotPasswordRequest true "Email of account"
+// @Router /auth/forgot-password [post]
+func (h *Handler) ForgotUserPassword(w http.ResponseWriter, r *http.Request) {
+	var request models.ForgotPasswordRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	if err := h.ForgotPassword(r.Context(), request.Email); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// @Summary Reset password
+// @Tags users
+// @Description Set a new password with the token from the reset email, all sessions are ended
+// @Accept  json
+// @Produce  json
+// @Param input body models.ResetPasswordRequest true "Token and new password"
+// @Router /auth/reset-password [post]
+func (h *Handler) ResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	var request models.ResetPasswordRequest
+	if !h.decodeRequest(w, r, &request, nil) {
+		return
+	}
+	if err := validate.Struct(request); err != nil {
+		writeError(w, r, validationError(err))
+		return
+	}
+	if err := h.ResetPassword(r.Context(), request.Token, request.Password); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -6,12 +6,21 @@ import (
 	"gohelp/cmd/handler"
 	"gohelp/internal/service/auth"
 	"gohelp/internal/service/forum"
+	"gohelp/internal/service/moderation"
+	"gohelp/internal/service/notification"
+	"gohelp/internal/service/screening"
 	"gohelp/internal/storage"
+	"gohelp/internal/storage/migrate"
 	"gohelp/internal/storage/mongo"
 	"gohelp/internal/storage/postgresql"
+	"gohelp/migrations"
 	"gohelp/pkg"
+	"gohelp/pkg/mailer"
+	"gohelp/pkg/pubsub"
+	"gohelp/pkg/ratelimit"
 	"log"
 	"net/http"
+	"os"
 	"time"
 )
 
@@ -35,12 +44,51 @@ func main() {
 		log.Fatalf("db is not connected")
 	}
 	forumdb := mongodb.Database("forum")
+	postgresMigrations, err := migrate.NewPostgres(db, migrations.Postgres())
+	if err != nil {
+		log.Fatalf("failed to load migrations: %v", err)
+	}
+	runners := []migrate.Runner{postgresMigrations, migrate.NewMongo(forumdb, migrations.Mongo)}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), runners, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := migrate.CheckCurrent(ctx, runners...); err != nil {
+		log.Fatalf("%v, run the migrate up command first", err)
+	}
 	forumRepo := mongo.NewForumStorage(forumdb, mongodb)
 	userRepo := postgresql.NewUserRepository(db)
-	userService := auth.NewUserService(userRepo)
-	forumService := forum.NewForumService(forumRepo)
-	userHandler := handler.NewHandler(userService, forumService)
-	pkg.InitOAuth()
+	keyring, err := auth.LoadKeyring()
+	if err != nil {
+		log.Fatalf("failed to load token keys: %v", err)
+	}
+	mail, err := mailer.FromEnv()
+	if err != nil {
+		log.Fatalf("failed to set up mailer: %v", err)
+	}
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	userService := auth.NewUserService(userRepo, keyring, auth.PasswordsFromEnv(), mail, baseURL)
+	screeningPipeline, err := screening.FromEnv(forumRepo)
+	if err != nil {
+		log.Fatalf("failed to set up content screening: %v", err)
+	}
+	hub := pubsub.NewHub()
+	notificationService := notification.NewNotificationService(postgresql.NewNotificationRepository(db), hub)
+	forumService := forum.NewForumService(forumRepo, userRepo, postgresql.NewSubscriptionRepository(db), screeningPipeline, notificationService, hub)
+	moderationService := moderation.NewModerationService(postgresql.NewReportRepository(db), forumRepo, userRepo, userService, forumService)
+	limiter, err := ratelimit.FromEnv()
+	if err != nil {
+		log.Fatalf("failed to set up rate limits: %v", err)
+	}
+	userHandler := handler.NewHandler(userService, forumService, moderationService, notificationService, limiter, hub)
+	if err = pkg.InitOAuth(baseURL); err != nil {
+		log.Fatalf("failed to set up oauth providers: %v", err)
+	}
 	log.Fatal(http.ListenAndServe(":8080", userHandler.InitRoutes()))
 
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohelp/internal/storage/migrate"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up                         apply all pending migrations
+  down <postgres|mongo> [n]  revert the last n (default 1) migrations of one database
+  status                     list migrations and when they were applied`
+
+// runMigrate is the migrate subcommand of the server binary.
+func runMigrate(ctx context.Context, runners []migrate.Runner, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	switch args[0] {
+	case "up":
+		for _, r := range runners {
+			done, err := r.Up(ctx)
+			printMigrations(r.Name(), "applied", done)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "down":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		steps := 1
+		if len(args) > 2 {
+			n, err := strconv.Atoi(args[2])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[2])
+			}
+			steps = n
+		}
+		for _, r := range runners {
+			if r.Name() != args[1] {
+				continue
+			}
+			done, err := r.Down(ctx, steps)
+			printMigrations(r.Name(), "reverted", done)
+			return err
+		}
+		return fmt.Errorf("unknown database %q", args[1])
+	case "status":
+		for _, r := range runners {
+			status, err := r.Status(ctx)
+			if err != nil {
+				return err
+			}
+			for _, s := range status {
+				applied := "pending"
+				if s.AppliedAt != nil {
+					applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Printf("%-8s %04d_%-40s %s\n", r.Name(), s.Version, s.Name, applied)
+			}
+		}
+		return nil
+	}
+	return errors.New(migrateUsage)
+}
+
+func printMigrations(db, action string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		fmt.Fprintf(os.Stdout, "%s: nothing %s\n", db, action)
+	}
+	for _, m := range migrations {
+		fmt.Fprintf(os.Stdout, "%s: %s %04d_%s\n", db, action, m.Version, m.Name)
+	}
+}
@@ -15,9 +15,22 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
+        "/.well-known/paseto-keys": {
+            "get": {
+                "description": "Ed25519 keys that verify v2.public access tokens, the key of a token is named by the kid in its footer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Public keys of tokens",
+                "responses": {}
+            }
+        },
+        "/auth/forgot-password": {
             "post": {
-                "description": "create account",
+                "description": "Send a password reset link, the answer is the same whether the account exists or not",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,124 +40,93 @@ const docTemplate = `{
                 "tags": [
                     "users"
                 ],
-                "summary": "SignIn",
+                "summary": "Forgot password",
                 "parameters": [
                     {
-                        "enum": [
-                            "classic",
-                            "google"
-                        ],
-                        "type": "string",
-                        "description": "Authorization method",
-                        "name": "auth_method",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "your email",
-                        "name": "email",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "your password",
-                        "name": "password",
-                        "in": "query"
+                        "description": "Email of account",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ForgotPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "create account",
-                "consumes": [
-                    "application/json"
+        "/auth/identities": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Provider accounts current user can sign in with and the providers that can be linked",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "users"
                 ],
-                "summary": "SignUp",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "your username",
-                        "name": "username",
-                        "in": "query",
-                        "required": true
-                    },
+                "summary": "Linked accounts",
+                "responses": {}
+            }
+        },
+        "/auth/identities/{provider}": {
+            "delete": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "your password",
-                        "name": "password",
-                        "in": "query",
-                        "required": true
-                    },
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop signing in with the provider. The only way to sign in can't be unlinked",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Unlink provider",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "your email",
-                        "name": "email",
-                        "in": "query",
+                        "description": "Name of the provider",
+                        "name": "provider",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {}
             }
         },
-        "/discuss/comments": {
+        "/auth/identities/{provider}/link": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "You can comment a discussion",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a link that connects an account of the provider to current user. It works once, for 10 minutes, and only in the browser that got it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "discussions"
+                    "users"
                 ],
-                "summary": "Comment discussion",
+                "summary": "Link provider",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "related content",
-                        "name": "related_to",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Id of element",
-                        "name": "discussionID",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Your comment",
-                        "name": "content",
-                        "in": "query",
+                        "description": "Name of the provider",
+                        "name": "provider",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {}
             }
         },
-        "/discuss/comments/delete": {
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+        "/auth/login": {
+            "post": {
+                "description": "Log in. Failed logins in a row lock the account for growing periods, answered with 429 and Retry-After",
                 "consumes": [
                     "application/json"
                 ],
@@ -152,28 +134,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "discussions"
+                    "users"
                 ],
-                "summary": "Delete comment",
+                "summary": "SignIn",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Id of comment",
-                        "name": "comment_id",
-                        "in": "query",
-                        "required": true
+                        "description": "Authorization method and credentials for the classic one",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SignInRequest"
+                        }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/discuss/comments/edit": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+        "/auth/logout": {
+            "post": {
+                "description": "End the session of refresh token",
                 "consumes": [
                     "application/json"
                 ],
@@ -181,36 +161,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "discussions"
+                    "users"
                 ],
-                "summary": "Update comment",
+                "summary": "Logout",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Id of comment",
-                        "name": "comment_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "New content field",
-                        "name": "content",
-                        "in": "query",
-                        "required": true
+                        "description": "Refresh token of session",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshTokenRequest"
+                        }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/discuss/discussions": {
+        "/auth/logout-all": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "You can post new discussion",
+                "description": "End all sessions of current user and revoke their access tokens",
                 "consumes": [
                     "application/json"
                 ],
@@ -218,35 +193,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "discussions"
+                    "users"
                 ],
-                "summary": "Create New Discussion",
+                "summary": "Logout everywhere",
+                "responses": {}
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange refresh token for a new access token and a new refresh token, the old refresh token stops working",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Refresh session",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Title of discussion",
-                        "name": "title",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Describe your problem here",
-                        "name": "content",
-                        "in": "query",
-                        "required": true
+                        "description": "Refresh token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshTokenRequest"
+                        }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/discuss/discussions/delete": {
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+        "/auth/register": {
+            "post": {
+                "description": "create account",
                 "consumes": [
                     "application/json"
                 ],
@@ -254,28 +236,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "discussions"
+                    "users"
                 ],
-                "summary": "Update discussion",
+                "summary": "SignUp",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Id of discussion",
-                        "name": "discussion_id",
-                        "in": "query",
-                        "required": true
+                        "description": "New account",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SignUp"
+                        }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/discuss/discussions/edit": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+        "/auth/reset-password": {
+            "post": {
+                "description": "Set a new password with the token from the reset email, all sessions are ended",
                 "consumes": [
                     "application/json"
                 ],
@@ -283,21 +263,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "discussions"
+                    "users"
                 ],
-                "summary": "Update discussion",
+                "summary": "Reset password",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Id of discussion",
-                        "name": "discussion_id",
-                        "in": "query",
-                        "required": true
-                    },
+                        "description": "Token and new password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/auth/verify-email": {
+            "get": {
+                "description": "Confirm email with the link sent on sign up",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Verify email",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "New content field",
-                        "name": "content",
+                        "description": "Token from the email",
+                        "name": "token",
                         "in": "query",
                         "required": true
                     }
@@ -305,71 +302,79 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/discuss/vote": {
+        "/auth/verify-email/resend": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Submit a vote with either \"like\" or \"dislike\"",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Send a new verification link to the email of current user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "discussions"
+                    "users"
                 ],
-                "summary": "Submit a vote",
+                "summary": "Resend verification email",
+                "responses": {}
+            }
+        },
+        "/auth/{provider}": {
+            "get": {
+                "description": "Redirect to an OAuth provider, the callback answers with tokens. With link the provider account is linked to the user who asked for the link instead, in the browser that asked for it",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Sign in with a provider",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Id of discussion or comment",
-                        "name": "ElementId",
-                        "in": "query",
+                        "description": "Name of the provider",
+                        "name": "provider",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "enum": [
-                            "like",
-                            "dislike"
-                        ],
-                        "type": "string",
-                        "description": "The type of vote. Can be either 'like' or 'dislike'.",
-                        "name": "vote",
-                        "in": "query",
-                        "required": true
+                        "type": "boolean",
+                        "description": "Continue the link started with /auth/identities/{provider}/link",
+                        "name": "link",
+                        "in": "query"
                     }
                 ],
                 "responses": {}
             }
         },
-        "/discussions": {
+        "/auth/{provider}/callback": {
             "get": {
-                "description": "Get all discussions on site",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Where the provider sends the user back to. Answers with tokens, or with the linked account when linking",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "discussions"
+                    "users"
+                ],
+                "summary": "OAuth callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all discussions",
                 "responses": {}
             }
         },
-        "/getdiscussion": {
-            "get": {
+        "/discuss/comments": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get full display of discussion with comments",
+                "description": "You can comment a discussion. A comment the spam filter flags is held for moderators (202, status \"held\") and shows up once approved",
                 "consumes": [
                     "application/json"
                 ],
@@ -379,22 +384,28 @@ const docTemplate = `{
                 "tags": [
                     "discussions"
                 ],
-                "summary": "Get full discussion",
+                "summary": "Comment discussion",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Id of discussion",
-                        "name": "discussion_id",
-                        "in": "query",
-                        "required": true
+                        "description": "New comment",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateCommentRequest"
+                        }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/search": {
-            "get": {
-                "description": "Get all discussions on site",
+        "/discuss/comments/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -404,26 +415,29 @@ const docTemplate = `{
                 "tags": [
                     "discussions"
                 ],
-                "summary": "Get all discussions",
+                "summary": "Delete comment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Search term",
-                        "name": "discussionName",
-                        "in": "query",
-                        "required": true
+                        "description": "Comment to delete",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.DeleteCommentRequest"
+                        }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/users/actions": {
+        "/discuss/comments/edit": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "An edit the spam filter flags is saved and the comment is held for moderators (202, status \"held\") until approved",
                 "consumes": [
                     "application/json"
                 ],
@@ -431,31 +445,1931 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "discussions"
                 ],
-                "summary": "Change status of user",
+                "summary": "Update comment",
                 "parameters": [
                     {
+                        "description": "New content of comment",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/discuss/discussions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "You can post new discussion. A discussion the spam filter flags is held for moderators (202, status \"held\") and shows up once approved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discussions"
+                ],
+                "summary": "Create New Discussion",
+                "parameters": [
+                    {
+                        "description": "New discussion",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateDiscussionRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/discuss/discussions/accept": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Author of discussion marks a top-level comment as the accepted answer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discussions"
+                ],
+                "summary": "Accept answer",
+                "parameters": [
+                    {
+                        "description": "Accepted comment, empty comment_id clears it",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AcceptAnswerRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/discuss/discussions/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discussions"
+                ],
+                "summary": "Update discussion",
+                "parameters": [
+                    {
+                        "description": "Discussion to delete",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.DeleteDiscussionRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/discuss/discussions/edit": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An edit the spam filter flags is saved and the discussion is held for moderators (202, status \"held\") until approved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discussions"
+                ],
+                "summary": "Update discussion",
+                "parameters": [
+                    {
+                        "description": "New content of discussion",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateDiscussionRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/discuss/discussions/tags": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discussions"
+                ],
+                "summary": "Update tags of discussion",
+                "parameters": [
+                    {
+                        "description": "New tags of discussion, empty list clears them",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateDiscussionTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/discuss/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Edit history of discussion or comment, available to its author and moderators",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Get revisions",
+                "parameters": [
+                    {
+                        "enum": [
+                            "discussion",
+                            "comment"
+                        ],
                         "type": "string",
-                        "description": "Id of User",
-                        "name": "user_id",
+                        "description": "Type of post",
+                        "name": "post_type",
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Id of post",
+                        "name": "post_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/discuss/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Word by word difference between two versions of discussion or comment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Diff revisions",
+                "parameters": [
                     {
                         "enum": [
-                            "ban",
-                            "unban"
+                            "discussion",
+                            "comment"
                         ],
                         "type": "string",
-                        "description": "The type of action. Can be either 'ban' or 'unban'.",
-                        "name": "action",
+                        "description": "Type of post",
+                        "name": "post_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of post",
+                        "name": "post_id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare to, current version by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/discuss/revisions/rollback": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moderator restores content of an earlier version, the rollback is saved as a new revision. A rollback the spam filter flags holds the post for review (202, status \"held\")",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Roll back post",
+                "parameters": [
+                    {
+                        "description": "Post and version to restore",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RollbackPostRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/discuss/vote": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Submit a vote with either \"like\" or \"dislike\", or withdraw it with \"none\"",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discussions"
+                ],
+                "summary": "Submit a vote",
+                "parameters": [
+                    {
+                        "description": "Vote",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.VoteRequest"
+                        }
                     }
                 ],
                 "responses": {}
             }
+        },
+        "/discussions": {
+            "get": {
+                "description": "Get all discussions on site page by page",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discussions"
+                ],
+                "summary": "Get all discussions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of discussions per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor value from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "newest",
+                            "most-liked",
+                            "most-commented",
+                            "recently-active"
+                        ],
+                        "type": "string",
+                        "description": "Order of discussions",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated tags, discussions must have all of them",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only resolved (true) or only open (false) discussions",
+                        "name": "resolved",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Server-Sent Events of a discussion: comment.created, comment.edited, comment.deleted and votes.changed. With an access token the stream carries the notification events of the user too. Either discussion_id or a token is required.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Event stream",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Discussion ID",
+                        "name": "discussion_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access token, for clients that cannot set the Authorization header",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/feed": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "New discussions with a followed tag or by a followed user, and new comments in a followed discussion or by a followed user, newest first. Posts of the user are left out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of posts per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor value from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/getdiscussion": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get full display of discussion with comments",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discussions"
+                ],
+                "summary": "Get full discussion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of discussion",
+                        "name": "discussion_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/held": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Discussions and comments the spam filter held, the oldest first, with what the filter found",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Held posts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of posts per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor value from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/held/{type}/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Publish a discussion or comment the spam filter held",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Approve held post",
+                "parameters": [
+                    {
+                        "enum": [
+                            "discussion",
+                            "comment"
+                        ],
+                        "type": "string",
+                        "description": "Post type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional note",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewHeldRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/held/{type}/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Keep a discussion or comment the spam filter held hidden for good",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Reject held post",
+                "parameters": [
+                    {
+                        "enum": [
+                            "discussion",
+                            "comment"
+                        ],
+                        "type": "string",
+                        "description": "Post type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional note",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewHeldRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Notifications of the user, the latest first, with the number of unread ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of notifications per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor value from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notifications/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Every notification type with whether the user receives it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Notification preferences",
+                "responses": {}
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Switch notification types on or off, types left out keep their setting. Types are reply, mention, answer_accepted, vote and activity",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Update notification preferences",
+                "parameters": [
+                    {
+                        "description": "Types to switch, for example {\\",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationPreferences"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notifications/read-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark all notifications read",
+                "responses": {}
+            }
+        },
+        "/notifications/{id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark notification read",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/reports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Open reports, the most severe and most reported first, or resolved reports, the latest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Moderation queue",
+                "parameters": [
+                    {
+                        "enum": [
+                            "open",
+                            "resolving",
+                            "resolved"
+                        ],
+                        "type": "string",
+                        "description": "Status of reports",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of reports per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor value from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Flag a discussion, comment or user for moderators. Every user can flag the same target once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Report",
+                "parameters": [
+                    {
+                        "description": "What is reported and why",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReportRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/reports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Report with every flag and the audit log of its resolutions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Report details",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/reports/{id}/resolve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Dismiss the report, delete the reported content or suspend its author. The resolution is kept in the audit log. A report another moderator is resolving answers 409",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Resolve report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Action, note and the suspension for the suspend action",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResolveReportRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "Get all discussions on site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discussions"
+                ],
+                "summary": "Get all discussions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search term",
+                        "name": "discussionName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated tags, discussions must have all of them",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only resolved (true) or only open (false) discussions",
+                        "name": "resolved",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Discussions, tags and users the user follows, the latest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Subscriptions",
+                "responses": {}
+            }
+        },
+        "/subscriptions/{type}/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Follow a discussion, a tag or a user to get notified about new posts there and see them in the feed. Following again changes nothing",
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Follow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "What to follow: discussion, tag or user",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Discussion ID, tag name or user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Unfollow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "What to unfollow: discussion, tag or user",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Discussion ID, tag name or user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Get tag catalog with the number of discussions using every tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get tags",
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add new tag to the catalog",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Create tag",
+                "parameters": [
+                    {
+                        "description": "New tag",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTagRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tags/describe": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change description of tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Describe tag",
+                "parameters": [
+                    {
+                        "description": "New description of tag",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.DescribeTagRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tags/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace source tag with target tag on every discussion and remove source from the catalog",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Merge tags",
+                "parameters": [
+                    {
+                        "description": "Source tag is merged into target tag",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.MergeTagRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tags/rename": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rename tag in the catalog and on every discussion",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Rename tag",
+                "parameters": [
+                    {
+                        "description": "Current and new name of tag",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RenameTagRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/actions": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ban for good and wipe the content, or lift every sanction. Deprecated, use /users/{id}/sanctions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change status of user",
+                "parameters": [
+                    {
+                        "description": "User and action",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UsersActionsRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/reputation/recompute": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rebuild reputation of every user from votes and accepted answers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Recompute reputation",
+                "responses": {}
+            }
+        },
+        "/users/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Roles from the least to the most powerful with the permissions they grant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Roles",
+                "responses": {}
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Public profile of user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Id of user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/{id}/comments": {
+            "get": {
+                "description": "Comments written by user, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get comments of user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Id of user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of comments per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor value from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/{id}/content/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Bring back the content hidden by sanctions of the user that were lifted or have expired. What the user or moderators deleted stays deleted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Restore content of user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/{id}/discussions": {
+            "get": {
+                "description": "Discussions started by user, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get discussions of user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Id of user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of discussions per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor value from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Administrator changes the role of another user, the new role applies to the next request of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Assign role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AssignRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/{id}/sanctions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Every sanction of the user, the latest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Sanction history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Suspend the user until expires_at, or for good without it. The content of the user stays unless wipe_content is set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Suspend user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason, end and whether to wipe content",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SanctionRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/{id}/sanctions/lift": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lift every active sanction of the user. With restore_content the content wiped by earlier sanctions comes back",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Lift sanctions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason and whether to restore content",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LiftSanctionRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        }
+    },
+    "definitions": {
+        "handler.AcceptAnswerRequest": {
+            "type": "object",
+            "required": [
+                "discussion_id"
+            ],
+            "properties": {
+                "comment_id": {
+                    "type": "string"
+                },
+                "discussion_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AssignRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreateCommentRequest": {
+            "type": "object",
+            "required": [
+                "content",
+                "discussionID"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "discussionID": {
+                    "type": "string"
+                },
+                "related_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreateDiscussionRequest": {
+            "type": "object",
+            "required": [
+                "content",
+                "title"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 35
+                }
+            }
+        },
+        "handler.CreateTagRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DeleteCommentRequest": {
+            "type": "object",
+            "required": [
+                "comment_id"
+            ],
+            "properties": {
+                "comment_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DeleteDiscussionRequest": {
+            "type": "object",
+            "required": [
+                "discussion_id"
+            ],
+            "properties": {
+                "discussion_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DescribeTagRequest": {
+            "type": "object",
+            "required": [
+                "description",
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.MergeTagRequest": {
+            "type": "object",
+            "required": [
+                "source",
+                "target"
+            ],
+            "properties": {
+                "source": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RenameTagRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "new_name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "new_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RollbackPostRequest": {
+            "type": "object",
+            "required": [
+                "post_id",
+                "post_type",
+                "version"
+            ],
+            "properties": {
+                "post_id": {
+                    "type": "string"
+                },
+                "post_type": {
+                    "type": "string",
+                    "enum": [
+                        "discussion",
+                        "comment"
+                    ]
+                },
+                "version": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "handler.SignInRequest": {
+            "type": "object",
+            "properties": {
+                "auth_method": {
+                    "type": "string",
+                    "enum": [
+                        "classic",
+                        "google"
+                    ]
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UpdateCommentRequest": {
+            "type": "object",
+            "required": [
+                "comment_id"
+            ],
+            "properties": {
+                "comment_id": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string",
+                    "maxLength": 70
+                }
+            }
+        },
+        "handler.UpdateDiscussionRequest": {
+            "type": "object",
+            "required": [
+                "discussion_id"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "discussion_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UpdateDiscussionTagsRequest": {
+            "type": "object",
+            "required": [
+                "discussion_id"
+            ],
+            "properties": {
+                "discussion_id": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.UsersActionsRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "user_id"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "ban",
+                        "unban"
+                    ]
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.VoteRequest": {
+            "type": "object",
+            "required": [
+                "ElementId",
+                "vote"
+            ],
+            "properties": {
+                "ElementId": {
+                    "type": "string"
+                },
+                "vote": {
+                    "type": "string",
+                    "enum": [
+                        "like",
+                        "dislike",
+                        "none"
+                    ]
+                }
+            }
+        },
+        "models.CreateReportRequest": {
+            "type": "object",
+            "required": [
+                "category",
+                "target_id",
+                "target_type"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "spam",
+                        "offensive",
+                        "harassment",
+                        "illegal",
+                        "other"
+                    ]
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "type": "string",
+                    "enum": [
+                        "discussion",
+                        "comment",
+                        "user"
+                    ]
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "models.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LiftSanctionRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "restore_content": {
+                    "description": "RestoreContent brings back content wiped by sanctions of the user.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.NotificationPreferences": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "boolean"
+            }
+        },
+        "models.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 6
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResolveReportRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "dismiss",
+                        "delete",
+                        "suspend"
+                    ]
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "suspension": {
+                    "description": "Suspension is required by the suspend action.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SanctionRequest"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.ReviewHeldRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "models.SanctionRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt ends the suspension, without it the user is banned until\nthe sanction is lifted.",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "wipe_content": {
+                    "description": "WipeContent deletes everything the user wrote.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.SignUp": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 6
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 15,
+                    "minLength": 6
+                }
+            }
         }
     },
     "securityDefinitions": {
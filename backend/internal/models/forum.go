@@ -2,46 +2,151 @@ package models
 
 import "time"
 
+// Causes of deletion of discussions and comments. Content hidden by a ban
+// or with its discussion can be restored, content deleted by its author or
+// a moderator stays deleted. Posts the screening held are hidden the same
+// way until a moderator approves them, rejected ones stay hidden.
+const (
+	DeletedByAuthor       = "user-deleted"
+	DeletedByModerator    = "moderator-deleted"
+	DeletedByBan          = "ban-cascade"
+	DeletedWithDiscussion = "discussion-cascade"
+	HeldForReview         = "held-for-review"
+	RejectedInReview      = "review-rejected"
+)
+
+// Statuses of a new post, and of a held post after review.
+const (
+	PostPublished = "published"
+	PostHeld      = "held"
+	PostRejected  = "rejected"
+)
+
+// Screening is the verdict of the screening on a post it held: the total
+// score and what added to it.
+type Screening struct {
+	Score   int      `json:"score" bson:"score"`
+	Reasons []string `json:"reasons" bson:"reasons"`
+}
+
+// HeldPost is a discussion or a comment waiting for a moderator.
+// DiscussionID is the discussion a comment belongs to.
+type HeldPost struct {
+	Type         string     `json:"type"`
+	ID           string     `json:"id"`
+	DiscussionID string     `json:"discussion_id,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Content      string     `json:"content"`
+	AuthorID     int        `json:"author_id"`
+	Author       *Author    `json:"author,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Screening    *Screening `json:"screening"`
+}
+
+// HeldListQuery selects one page of the held posts, the oldest first.
+type HeldListQuery struct {
+	Limit  int
+	Cursor string
+}
+
+// ReviewHeldRequest is the decision of a moderator on a held post.
+type ReviewHeldRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
 type Discussion struct {
-	ID           string    `json:"id" bson:"_id,omitempty"`
-	Title        string    `json:"title" bson:"title"`
-	Content      string    `json:"content" bson:"content"`
-	AuthorID     int       `json:"author_id" bson:"author_id"`
-	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
-	Likes        []int     `json:"-" bson:"likes"`
-	LikesCount   int       `json:"likes" bson:"-"`
-	Dislikes     []int     `json:"-" bson:"dislikes"`
-	DisikesCount int       `json:"dislikes" bson:"-"`
-	Edited       bool      `json:"edited" bson:"edited"`
-	Deleted      bool      `json:"-" bson:"deleted"`
+	ID               string     `json:"id" bson:"_id,omitempty"`
+	Title            string     `json:"title" bson:"title"`
+	Content          string     `json:"content" bson:"content"`
+	Tags             []string   `json:"tags" bson:"tags"`
+	AuthorID         int        `json:"author_id" bson:"author_id"`
+	Author           *Author    `json:"author,omitempty" bson:"-"`
+	CreatedAt        time.Time  `json:"created_at" bson:"created_at"`
+	Likes            []int      `json:"-" bson:"likes"`
+	LikesCount       int        `json:"likes" bson:"likes_count"`
+	Dislikes         []int      `json:"-" bson:"dislikes"`
+	DisikesCount     int        `json:"dislikes" bson:"dislikes_count"`
+	CommentsCount    int64      `json:"comments_count" bson:"comments_count"`
+	LastActivityAt   time.Time  `json:"last_activity_at" bson:"last_activity_at"`
+	AcceptedAnswerID string     `json:"accepted_answer_id,omitempty" bson:"accepted_answer_id"`
+	Resolved         bool       `json:"resolved" bson:"resolved"`
+	Edited           bool       `json:"edited" bson:"edited"`
+	Revisions        int        `json:"revisions,omitempty" bson:"revisions,omitempty"`
+	Deleted          bool       `json:"-" bson:"deleted"`
+	DeletedCause     string     `json:"-" bson:"deleted_cause,omitempty"`
+	Screening        *Screening `json:"-" bson:"screening,omitempty"`
 }
 
 type Comment struct {
-	ID           string    `json:"id" bson:"_id,omitempty"`
-	DiscussionID string    `json:"-" bson:"discussion_id"`
-	RelatedTo    string    `json:"-" bson:"related_to"`
-	Content      string    `json:"content" bson:"content"`
-	AuthorID     int       `json:"author_id" bson:"author_id"`
-	Likes        []int     `json:"-" bson:"likes"`
-	LikesCount   int       `json:"likes" bson:"-"`
-	Dislikes     []int     `json:"-" bson:"dislikes"`
-	DisikesCount int       `json:"dislikes" bson:"-"`
-	Edited       bool      `json:"edited" bson:"edited"`
-	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
-	Deleted      bool      `json:"-" bson:"deleted"`
-	Children     []Comment `json:"children,omitempty" bson:"-"`
+	ID           string     `json:"id" bson:"_id,omitempty"`
+	DiscussionID string     `json:"discussion_id" bson:"discussion_id"`
+	RelatedTo    string     `json:"-" bson:"related_to"`
+	Content      string     `json:"content" bson:"content"`
+	AuthorID     int        `json:"author_id" bson:"author_id"`
+	Author       *Author    `json:"author,omitempty" bson:"-"`
+	Likes        []int      `json:"-" bson:"likes"`
+	LikesCount   int        `json:"likes" bson:"-"`
+	Dislikes     []int      `json:"-" bson:"dislikes"`
+	DisikesCount int        `json:"dislikes" bson:"-"`
+	Edited       bool       `json:"edited" bson:"edited"`
+	Revisions    int        `json:"revisions,omitempty" bson:"revisions,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" bson:"created_at"`
+	Deleted      bool       `json:"-" bson:"deleted"`
+	DeletedCause string     `json:"-" bson:"deleted_cause,omitempty"`
+	Screening    *Screening `json:"-" bson:"screening,omitempty"`
+	Accepted     bool       `json:"accepted,omitempty" bson:"-"`
+	Children     []Comment  `json:"children,omitempty" bson:"-"`
 }
 type DiscussionTopic struct {
-	ID           string `json:"id" bson:"_id,omitempty"`
-	Title        string `json:"title" bson:"title"`
-	Content      string `json:"content" bson:"content"`
-	Likes        []int  `json:"-" bson:"likes"`
-	LikesCount   int    `json:"likes" bson:"-"`
-	Dislikes     []int  `json:"-" bson:"dislikes"`
-	DisikesCount int    `json:"dislikes" bson:"-"`
+	ID             string    `json:"id" bson:"_id,omitempty"`
+	Title          string    `json:"title" bson:"title"`
+	Content        string    `json:"content" bson:"content"`
+	Tags           []string  `json:"tags" bson:"tags"`
+	AuthorID       int       `json:"author_id" bson:"author_id"`
+	Author         *Author   `json:"author,omitempty" bson:"-"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+	LikesCount     int       `json:"likes" bson:"likes_count"`
+	DisikesCount   int       `json:"dislikes" bson:"dislikes_count"`
+	CommentsCount  int64     `json:"-" bson:"comments_count"`
+	LastActivityAt time.Time `json:"-" bson:"last_activity_at"`
+	Resolved       bool      `json:"resolved" bson:"resolved"`
 }
 
 type DiscussionWithCount struct {
-	Discussion    DiscussionTopic
-	CommentsCount int64
+	Discussion     DiscussionTopic
+	CommentsCount  int64
+	LastActivityAt time.Time
 }
+
+const (
+	SortNewest         = "newest"
+	SortMostLiked      = "most-liked"
+	SortMostCommented  = "most-commented"
+	SortRecentlyActive = "recently-active"
+)
+
+// DiscussionFilter narrows the discussions returned by the list endpoints.
+// Resolved is nil when both resolved and open discussions are wanted.
+type DiscussionFilter struct {
+	Tags     []string
+	Resolved *bool
+}
+
+// DiscussionListQuery describes one page of the discussions listing.
+// Cursor is the opaque value returned as next_cursor by the previous page.
+type DiscussionListQuery struct {
+	DiscussionFilter
+	Limit  int
+	Cursor string
+	Sort   string
+}
+
+// Tag is an entry of the tag catalog. The name is the tag itself and is
+// used as the document id, so it is unique.
+type Tag struct {
+	Name        string `json:"name" bson:"_id"`
+	Description string `json:"description" bson:"description"`
+	UsageCount  int64  `json:"usage_count" bson:"-"`
+}
+
+const MaxTagsPerDiscussion = 5
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// UserIdentity links an account of an OAuth provider to a user. Subject is
+// the id of the account at the provider, which unlike the email never
+// changes hands.
+type UserIdentity struct {
+	ID        int64     `json:"-" db:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	Provider  string    `json:"provider" db:"provider"`
+	Subject   string    `json:"subject" db:"subject"`
+	Email     string    `json:"email" db:"email"`
+	CreatedAt time.Time `json:"linked_at" db:"created_at"`
+}
+
+// ExternalIdentity is what a provider told about the account the user
+// signed in with.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	AvatarURL     string
+}
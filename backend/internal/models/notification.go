@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Types of notifications.
+const (
+	NotifyReply    = "reply"
+	NotifyMention  = "mention"
+	NotifyAccepted = "answer_accepted"
+	NotifyVote     = "vote"
+	// NotifyActivity is about a new post in something the user follows.
+	// Detail carries what was followed: a discussion, a tag or a user.
+	NotifyActivity = "activity"
+)
+
+// NotificationTypes lists every type users can switch off.
+var NotificationTypes = []string{NotifyReply, NotifyMention, NotifyAccepted, NotifyVote, NotifyActivity}
+
+// Notification tells the user about something another user, the actor,
+// did. CommentID is empty for notifications about a discussion, Detail
+// carries the vote type of vote notifications.
+type Notification struct {
+	ID           int64      `json:"id" db:"id"`
+	UserID       int        `json:"-" db:"user_id"`
+	Type         string     `json:"type" db:"type"`
+	ActorID      *int       `json:"actor_id,omitempty" db:"actor_id"`
+	DiscussionID string     `json:"discussion_id,omitempty" db:"discussion_id"`
+	CommentID    string     `json:"comment_id,omitempty" db:"comment_id"`
+	Detail       string     `json:"detail,omitempty" db:"detail"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	ReadAt       *time.Time `json:"read_at,omitempty" db:"read_at"`
+}
+
+// NotificationListQuery selects one page of notifications of the user,
+// the latest first.
+type NotificationListQuery struct {
+	UserID     int
+	UnreadOnly bool
+	Limit      int
+	Cursor     string
+}
+
+// NotificationPreferences tells for every type whether the user receives
+// it.
+type NotificationPreferences map[string]bool
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// TargetUser is reported as a whole, discussions and comments are reported
+// with PostDiscussion and PostComment.
+const TargetUser = "user"
+
+// Categories of reports.
+const (
+	ReportSpam       = "spam"
+	ReportOffensive  = "offensive"
+	ReportHarassment = "harassment"
+	ReportIllegal    = "illegal"
+	ReportOther      = "other"
+)
+
+// ReportSeverity orders categories for the moderation queue, a report is
+// as severe as the worst category it was flagged with.
+var ReportSeverity = map[string]int{
+	ReportOther:      1,
+	ReportSpam:       2,
+	ReportOffensive:  3,
+	ReportHarassment: 4,
+	ReportIllegal:    5,
+}
+
+// States of reports. A report is resolving while a moderator acts on it.
+const (
+	ReportOpen      = "open"
+	ReportResolving = "resolving"
+	ReportResolved  = "resolved"
+)
+
+// What a moderator can do with a report.
+const (
+	ResolveDismiss = "dismiss"
+	ResolveDelete  = "delete"
+	ResolveSuspend = "suspend"
+)
+
+// Report collects the flags of one target. AuthorID is the author of the
+// reported content, or the reported user.
+type Report struct {
+	ID           int64              `json:"id" db:"id"`
+	TargetType   string             `json:"target_type" db:"target_type"`
+	TargetID     string             `json:"target_id" db:"target_id"`
+	AuthorID     int                `json:"author_id" db:"author_id"`
+	Severity     int                `json:"severity" db:"severity"`
+	ReportsCount int                `json:"reports_count" db:"reports_count"`
+	Status       string             `json:"status" db:"status"`
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" db:"updated_at"`
+	ResolvedAt   *time.Time         `json:"resolved_at,omitempty" db:"resolved_at"`
+	Flags        []ReportFlag       `json:"flags,omitempty" db:"-"`
+	Resolutions  []ReportResolution `json:"resolutions,omitempty" db:"-"`
+}
+
+type ReportFlag struct {
+	ReporterID int       `json:"reporter_id" db:"reporter_id"`
+	Category   string    `json:"category" db:"category"`
+	Text       string    `json:"text,omitempty" db:"text"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReportResolution is a record of the audit log of reports.
+type ReportResolution struct {
+	ID          int64     `json:"id" db:"id"`
+	ReportID    int64     `json:"report_id" db:"report_id"`
+	ModeratorID *int      `json:"moderator_id" db:"moderator_id"`
+	Action      string    `json:"action" db:"action"`
+	Note        string    `json:"note,omitempty" db:"note"`
+	SanctionID  *int64    `json:"sanction_id,omitempty" db:"sanction_id"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateReportRequest struct {
+	TargetType string `json:"target_type" validate:"required,oneof=discussion comment user" enums:"discussion,comment,user"`
+	TargetID   string `json:"target_id" validate:"required"`
+	Category   string `json:"category" validate:"required,oneof=spam offensive harassment illegal other" enums:"spam,offensive,harassment,illegal,other"`
+	Text       string `json:"text" validate:"max=1000"`
+}
+
+type ResolveReportRequest struct {
+	Action string `json:"action" validate:"required,oneof=dismiss delete suspend" enums:"dismiss,delete,suspend"`
+	Note   string `json:"note" validate:"max=1000"`
+	// Suspension is required by the suspend action.
+	Suspension *SanctionRequest `json:"suspension"`
+}
+
+// ReportListQuery describes one page of the moderation queue or of the
+// resolved reports.
+type ReportListQuery struct {
+	Status string
+	Limit  int
+	Cursor string
+}
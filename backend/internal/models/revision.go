@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+const (
+	PostDiscussion = "discussion"
+	PostComment    = "comment"
+)
+
+// Revision keeps the content a post had before one of its edits. Versions
+// of a post are numbered from 1, the original content, so revision N holds
+// version N and the current content is version Revisions+1.
+type Revision struct {
+	ID       string    `json:"-" bson:"_id,omitempty"`
+	PostID   string    `json:"post_id" bson:"post_id"`
+	PostType string    `json:"post_type" bson:"post_type"`
+	Version  int       `json:"version" bson:"version"`
+	Content  string    `json:"content" bson:"content"`
+	EditorID int       `json:"editor_id" bson:"editor_id"`
+	EditedAt time.Time `json:"edited_at" bson:"edited_at"`
+}
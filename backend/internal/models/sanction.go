@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// States of a sanction.
+const (
+	SanctionActive  = "active"
+	SanctionExpired = "expired"
+	SanctionLifted  = "lifted"
+)
+
+// Sanction suspends a user. Without ExpiresAt it lasts until it is lifted.
+type Sanction struct {
+	ID              int64      `json:"id" db:"id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	Reason          string     `json:"reason" db:"reason"`
+	IssuedBy        *int       `json:"issued_by" db:"issued_by"`
+	StartsAt        time.Time  `json:"starts_at" db:"starts_at"`
+	ExpiresAt       *time.Time `json:"expires_at" db:"expires_at"`
+	ContentWiped    bool       `json:"content_wiped" db:"content_wiped"`
+	ContentRestored bool       `json:"content_restored" db:"content_restored"`
+	LiftedAt        *time.Time `json:"lifted_at,omitempty" db:"lifted_at"`
+	LiftedBy        *int       `json:"lifted_by,omitempty" db:"lifted_by"`
+	LiftReason      string     `json:"lift_reason,omitempty" db:"lift_reason"`
+	State           string     `json:"state" db:"-"`
+}
+
+// StateAt tells if the sanction is active, has expired or was lifted.
+func (s *Sanction) StateAt(now time.Time) string {
+	switch {
+	case s.LiftedAt != nil:
+		return SanctionLifted
+	case s.ExpiresAt != nil && !now.Before(*s.ExpiresAt):
+		return SanctionExpired
+	}
+	return SanctionActive
+}
+
+type SanctionRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+	// ExpiresAt ends the suspension, without it the user is banned until
+	// the sanction is lifted.
+	ExpiresAt *time.Time `json:"expires_at"`
+	// WipeContent deletes everything the user wrote.
+	WipeContent bool `json:"wipe_content"`
+}
+
+type LiftSanctionRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+	// RestoreContent brings back content wiped by sanctions of the user.
+	RestoreContent bool `json:"restore_content"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// RefreshToken is a stored refresh token. Only the hash of the token is
+// kept. Every refresh replaces the token with a new one of the same
+// session, so a session is the chain of tokens started by one login.
+type RefreshToken struct {
+	ID        int64      `db:"id"`
+	UserID    int        `db:"user_id"`
+	SessionID string     `db:"session_id"`
+	TokenHash string     `db:"token_hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+// TokenPair is returned to the client on login and on refresh.
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// Purposes of single-use tokens. Link tokens are not mailed, they carry
+// the signed in user through the redirects of an OAuth provider. Their
+// purpose is followed by the name of the provider they are for.
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+	TokenLinkIdentity  = "link_identity"
+)
+
+// UserToken is a stored single-use token sent to the user by email. Like
+// refresh tokens it is kept only as a hash.
+type UserToken struct {
+	UserID    int
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6,max=30"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// TargetTag is followed by its name. Discussions and users are followed
+// as PostDiscussion and TargetUser.
+const TargetTag = "tag"
+
+// FollowTargets lists what users can follow.
+var FollowTargets = []string{PostDiscussion, TargetTag, TargetUser}
+
+// Subscription is a discussion, a tag or a user the user follows.
+type Subscription struct {
+	UserID     int       `json:"-" db:"user_id"`
+	TargetType string    `json:"type" db:"target_type"`
+	TargetID   string    `json:"id" db:"target_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// SubscriptionTarget is something that can be followed.
+type SubscriptionTarget struct {
+	Type string
+	ID   string
+}
+
+// FeedQuery selects one page of the feed of the user: the discussions
+// and comments posted in what the user follows, the latest first.
+type FeedQuery struct {
+	UserID      int
+	Discussions []string
+	Tags        []string
+	Users       []int
+	Limit       int
+	Cursor      string
+}
+
+// FeedItem is a discussion or a comment in the feed. DiscussionID is the
+// discussion a comment belongs to.
+type FeedItem struct {
+	Type         string    `json:"type"`
+	ID           string    `json:"id"`
+	DiscussionID string    `json:"discussion_id,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Content      string    `json:"content"`
+	Tags         []string  `json:"tags,omitempty"`
+	AuthorID     int       `json:"author_id"`
+	Author       *Author   `json:"author,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -1,17 +1,50 @@
 package models
 
+import "time"
+
 type User struct {
-	ID           int    `json:"id"`
-	Username     string `json:"username" validate:"required,min=6"`
-	Email        string `json:"email" validate:"required,min=6"`
-	Password     string `json:"password,omitempty" validate:"required,min=6"`
-	PasswordHash string `json:"-"`
-	Role         string `json:"role"`
-	Banned       bool   `json:"banned"`
+	ID            int       `json:"id"`
+	Username      string    `json:"username" validate:"required,min=6"`
+	Email         string    `json:"email" validate:"required,min=6"`
+	PasswordHash  string    `json:"-"`
+	Role          string    `json:"role"`
+	Banned        bool      `json:"banned"`
+	Reputation    int       `json:"reputation"`
+	AvatarURL     string    `json:"avatar_url"`
+	CreatedAt     time.Time `json:"created_at"`
+	EmailVerified bool      `json:"email_verified"`
+	TokenVersion  int       `json:"-"`
+}
+
+// HasPassword tells if the user can log in with a password. Accounts
+// created through OAuth have no password hash.
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// Author is the short public view of a user embedded into discussions and
+// comments.
+type Author struct {
+	ID         int    `json:"id"`
+	Username   string `json:"username"`
+	AvatarURL  string `json:"avatar_url,omitempty"`
+	Reputation int    `json:"reputation"`
+}
+
+// PublicProfile is what anyone can see about a user. It must never carry
+// the email or anything related to the password.
+type PublicProfile struct {
+	ID               int       `json:"id"`
+	Username         string    `json:"username"`
+	Role             string    `json:"role"`
+	Reputation       int       `json:"reputation"`
+	JoinedAt         time.Time `json:"joined_at"`
+	DiscussionsCount int64     `json:"discussions_count"`
+	CommentsCount    int64     `json:"comments_count"`
 }
 type SignUp struct {
 	Username string `json:"username" validate:"required,min=6,max=15"`
-	Email    string `json:"email" validate:"required,min=6,max=20"`
+	Email    string `json:"email" validate:"required,email,max=255"`
 	Password string `json:"password,omitempty" validate:"required,min=6,max=30"`
 }
 type LoginRequest struct {
@@ -19,7 +52,19 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// Roles of users, what each of them may do is decided by package rbac.
 const (
-	CustomerRole       string = "customer"
+	UserRole           string = "user"
+	TrustedRole        string = "trusted"
+	ModeratorRole      string = "moderator"
 	AdministrationRole string = "admin"
 )
+
+// Reputation received by the author of a discussion or a comment. Votes
+// of authors on their own content and accepted answers written by the
+// author of the discussion give nothing.
+const (
+	ReputationLike     = 10
+	ReputationDislike  = -2
+	ReputationAccepted = 15
+)
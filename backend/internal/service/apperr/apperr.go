@@ -0,0 +1,71 @@
+// Package apperr defines the kinds of errors services return to handlers.
+// A handler decides the response status by the kind only, so services must
+// wrap every error that is caused by the client into one of them.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotFound             = errors.New("not found")
+	ErrForbidden            = errors.New("forbidden")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrBanned               = errors.New("banned")
+	ErrConflict             = errors.New("conflict")
+	ErrValidation           = errors.New("validation failed")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrTooManyRequests      = errors.New("too many requests")
+)
+
+// Error is an error of a known kind with a message that is safe to show
+// to the client and optional details about it. RetryAfter tells the client
+// when to try again.
+type Error struct {
+	Kind       error
+	Message    string
+	Details    interface{}
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func New(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return New(ErrNotFound, format, args...)
+}
+
+func Forbidden(format string, args ...interface{}) error {
+	return New(ErrForbidden, format, args...)
+}
+
+func Unauthorized(format string, args ...interface{}) error {
+	return New(ErrUnauthorized, format, args...)
+}
+
+func Banned(format string, args ...interface{}) error {
+	return New(ErrBanned, format, args...)
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return New(ErrConflict, format, args...)
+}
+
+func Validation(format string, args ...interface{}) error {
+	return New(ErrValidation, format, args...)
+}
+
+func TooManyRequests(retryAfter time.Duration, format string, args ...interface{}) error {
+	return &Error{Kind: ErrTooManyRequests, Message: fmt.Sprintf(format, args...), RetryAfter: retryAfter}
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/pkg/mailer"
+	"log"
+	"net/url"
+	"time"
+)
+
+const (
+	verifyEmailTTL   = 48 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+var errInvalidEmailToken = apperr.Validation("invalid, expired or already used token")
+
+// sendUserToken stores a new single-use token for the user and mails them
+// a link with it.
+func (s *UserService) sendUserToken(ctx context.Context, user *models.User, purpose string, ttl time.Duration, link, subject, text string) error {
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	err = s.CreateUserToken(ctx, models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return fmt.Errorf("error during saving token: %v", err)
+	}
+	return s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf(text, user.Username, link+"?token="+url.QueryEscape(token)),
+	})
+}
+
+func (s *UserService) sendVerification(ctx context.Context, user *models.User) error {
+	return s.sendUserToken(ctx, user, models.TokenVerifyEmail, verifyEmailTTL,
+		s.baseURL+"/auth/verify-email",
+		"Confirm your email",
+		"Hi %s,\n\nconfirm your email by opening this link:\n%s\n\nThe link works for 48 hours.")
+}
+
+// ResendVerification mails a new verification link to the user.
+func (s *UserService) ResendVerification(ctx context.Context, userID int) error {
+	user, err := s.GetUserById(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error during getting user by id: %v", err)
+	}
+	if user.EmailVerified {
+		return apperr.Conflict("email is already verified")
+	}
+	if err = s.DiscardUserTokens(ctx, user.ID, models.TokenVerifyEmail); err != nil {
+		return fmt.Errorf("error during discarding tokens: %v", err)
+	}
+	if err = s.sendVerification(ctx, user); err != nil {
+		return fmt.Errorf("error during sending verification: %v", err)
+	}
+	return nil
+}
+
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.ConsumeUserToken(ctx, models.TokenVerifyEmail, hashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return errInvalidEmailToken
+	}
+	if err != nil {
+		return fmt.Errorf("error during checking token: %v", err)
+	}
+	if err = s.SetEmailVerified(ctx, userID); err != nil {
+		return fmt.Errorf("error during verifying email: %v", err)
+	}
+	return nil
+}
+
+// ForgotPassword mails a password reset link. The link opens the reset
+// page of the web client, which posts the token to /auth/reset-password.
+// It reports success for unknown emails too, so that it can't be used to
+// find out who has an account.
+func (s *UserService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error during getting user by email: %v", err)
+	}
+	if user.Banned {
+		return nil
+	}
+	err = s.sendUserToken(ctx, user, models.TokenResetPassword, resetPasswordTTL,
+		s.baseURL+"/reset-password",
+		"Reset your password",
+		"Hi %s,\n\nsomeone asked to reset your password. If it was you, open this link:\n%s\n\nThe link works for one hour. If it wasn't you, ignore this email.")
+	if err != nil {
+		return fmt.Errorf("error during sending password reset: %v", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password with the token from the reset email.
+// Other reset links stop working and every session is ended.
+func (s *UserService) ResetPassword(ctx context.Context, token, password string) error {
+	userID, err := s.ConsumeUserToken(ctx, models.TokenResetPassword, hashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return errInvalidEmailToken
+	}
+	if err != nil {
+		return fmt.Errorf("error during checking token: %v", err)
+	}
+	hashedPassword, err := s.passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+	if err = s.SetPassword(ctx, userID, hashedPassword); err != nil {
+		return fmt.Errorf("error during setting password: %v", err)
+	}
+	if err = s.DiscardUserTokens(ctx, userID, models.TokenResetPassword); err != nil {
+		return fmt.Errorf("error during discarding tokens: %v", err)
+	}
+	// The link proves that the user owns the email.
+	if err = s.SetEmailVerified(ctx, userID); err != nil {
+		return fmt.Errorf("error during verifying email: %v", err)
+	}
+	// The new password is known to the owner only, so the lock is lifted.
+	if err = s.ResetFailedLogins(ctx, userID); err != nil {
+		return fmt.Errorf("error during resetting failed logins: %v", err)
+	}
+	return s.LogoutAll(ctx, userID)
+}
+
+// registered sends the verification email to a new account. Failing to
+// send is only logged, the user can ask for another email later.
+func (s *UserService) registered(ctx context.Context, email string) {
+	user, err := s.GetUserByEmail(ctx, email)
+	if err == nil {
+		err = s.sendVerification(ctx, user)
+	}
+	if err != nil {
+		log.Printf("failed to send verification email to new user: %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package auth
+
+import "gohelp/internal/service/apperr"
+
+// Kinds of errors returned by UserService, check them with errors.Is.
+var (
+	ErrNotFound     = apperr.ErrNotFound
+	ErrUnauthorized = apperr.ErrUnauthorized
+	ErrBanned       = apperr.ErrBanned
+	ErrConflict     = apperr.ErrConflict
+	ErrValidation   = apperr.ErrValidation
+)
+
+var errInvalidCredentials = apperr.Unauthorized("invalid credentials")
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	// PurposeLocal keys encrypt tokens with a shared secret (v2.local).
+	PurposeLocal = "local"
+	// PurposePublic keys sign tokens with Ed25519 (v2.public), so anyone
+	// with the public key can verify them.
+	PurposePublic = "public"
+)
+
+// legacyKeyID names the key built from SYMMETRIC_KEY when no keyring file
+// is configured.
+const legacyKeyID = "default"
+
+// KeyConfig is one key in the file PASETO_KEYS_FILE points to. Key holds
+// the base64 encoded 32 byte secret of a local key or the 32 byte Ed25519
+// seed of a public key. A key with RetireAt is still accepted until then,
+// which gives tokens sealed with it time to expire after a rotation.
+type KeyConfig struct {
+	ID       string     `json:"id"`
+	Purpose  string     `json:"purpose"`
+	Key      string     `json:"key"`
+	RetireAt *time.Time `json:"retire_at,omitempty"`
+}
+
+// KeyringConfig lists the keys and names the one new tokens are issued
+// with.
+type KeyringConfig struct {
+	Active string      `json:"active"`
+	Keys   []KeyConfig `json:"keys"`
+}
+
+type pasetoKey struct {
+	id        string
+	purpose   string
+	symmetric []byte
+	private   ed25519.PrivateKey
+	public    ed25519.PublicKey
+	retireAt  *time.Time
+}
+
+func (k *pasetoKey) retired(now time.Time) bool {
+	return k.retireAt != nil && now.After(*k.retireAt)
+}
+
+// Keyring holds the keys tokens are issued and validated with.
+type Keyring struct {
+	active *pasetoKey
+	keys   map[string]*pasetoKey
+}
+
+// PublicKey is a verification key published for other services.
+type PublicKey struct {
+	KeyID    string     `json:"kid"`
+	Version  string     `json:"version"`
+	Purpose  string     `json:"purpose"`
+	Key      string     `json:"key"`
+	RetireAt *time.Time `json:"retire_at,omitempty"`
+}
+
+// LoadKeyring reads the keyring from the JSON file in PASETO_KEYS_FILE. If
+// it is not set, SYMMETRIC_KEY becomes the only local key.
+func LoadKeyring() (*Keyring, error) {
+	path := os.Getenv("PASETO_KEYS_FILE")
+	if path == "" {
+		secret := os.Getenv("SYMMETRIC_KEY")
+		return NewKeyring(KeyringConfig{
+			Active: legacyKeyID,
+			Keys: []KeyConfig{{
+				ID:      legacyKeyID,
+				Purpose: PurposeLocal,
+				Key:     base64.StdEncoding.EncodeToString([]byte(secret)),
+			}},
+		})
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error during reading keyring: %v", err)
+	}
+	var config KeyringConfig
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error during parsing keyring: %v", err)
+	}
+	return NewKeyring(config)
+}
+
+func NewKeyring(config KeyringConfig) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]*pasetoKey, len(config.Keys))}
+	for _, c := range config.Keys {
+		if c.ID == "" {
+			return nil, errors.New("key without id")
+		}
+		if _, ok := keyring.keys[c.ID]; ok {
+			return nil, fmt.Errorf("key %q is listed twice", c.ID)
+		}
+		raw, err := base64.StdEncoding.DecodeString(c.Key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64: %v", c.ID, err)
+		}
+		if len(raw) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes long", c.ID)
+		}
+		key := &pasetoKey{id: c.ID, purpose: c.Purpose, retireAt: c.RetireAt}
+		switch c.Purpose {
+		case PurposeLocal:
+			key.symmetric = raw
+		case PurposePublic:
+			key.private = ed25519.NewKeyFromSeed(raw)
+			key.public = key.private.Public().(ed25519.PublicKey)
+		default:
+			return nil, fmt.Errorf("key %q has unknown purpose %q", c.ID, c.Purpose)
+		}
+		keyring.keys[c.ID] = key
+	}
+
+	active, ok := keyring.keys[config.Active]
+	if !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", config.Active)
+	}
+	if active.retireAt != nil {
+		return nil, fmt.Errorf("active key %q cannot be retired", config.Active)
+	}
+	keyring.active = active
+	return keyring, nil
+}
+
+// PublicKeys returns the keys that verify public tokens and are not
+// retired yet.
+func (k *Keyring) PublicKeys() []PublicKey {
+	now := time.Now()
+	keys := []PublicKey{}
+	for _, key := range k.keys {
+		if key.purpose != PurposePublic || key.retired(now) {
+			continue
+		}
+		keys = append(keys, PublicKey{
+			KeyID:    key.id,
+			Version:  "v2",
+			Purpose:  PurposePublic,
+			Key:      base64.RawURLEncoding.EncodeToString(key.public),
+			RetireAt: key.retireAt,
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].KeyID < keys[j].KeyID })
+	return keys
+}
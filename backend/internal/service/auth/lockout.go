@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"gohelp/internal/service/apperr"
+	"log"
+	"time"
+)
+
+// Failed logins in a row lock the account out of password logins. The
+// first lockFreeFailures failures cost nothing, every further one locks
+// the account for twice as long as the one before, up to maxLock.
+// Failures older than forgetFailuresAfter are forgotten.
+const (
+	lockFreeFailures    = 4
+	firstLock           = time.Minute
+	maxLock             = time.Hour
+	forgetFailuresAfter = 24 * time.Hour
+)
+
+// lockDuration is how long the failures in a row lock the account.
+func lockDuration(failures int) time.Duration {
+	if failures <= lockFreeFailures {
+		return 0
+	}
+	lock := firstLock
+	for i := lockFreeFailures + 1; i < failures && lock < maxLock; i++ {
+		lock *= 2
+	}
+	if lock > maxLock {
+		lock = maxLock
+	}
+	return lock
+}
+
+func loginLocked(wait time.Duration) error {
+	return apperr.TooManyRequests(wait, "too many failed logins, try again in %s", wait.Round(time.Second))
+}
+
+// checkLoginLock refuses the login while the account is locked, even with
+// the right password.
+func (s *UserService) checkLoginLock(ctx context.Context, userID int) error {
+	until, err := s.LoginLockedUntil(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error during checking login lock: %v", err)
+	}
+	if wait := time.Until(until); wait > 0 {
+		return loginLocked(wait)
+	}
+	return nil
+}
+
+// loginFailed counts the failure and locks the account when it is one too
+// many. It returns the error to answer the login with.
+func (s *UserService) loginFailed(ctx context.Context, userID int) error {
+	failures, err := s.RecordFailedLogin(ctx, userID, forgetFailuresAfter)
+	if err != nil {
+		return fmt.Errorf("error during recording failed login: %v", err)
+	}
+	lock := lockDuration(failures)
+	if lock == 0 {
+		return errInvalidCredentials
+	}
+	if err = s.LockLogin(ctx, userID, time.Now().Add(lock)); err != nil {
+		return fmt.Errorf("error during locking login: %v", err)
+	}
+	return loginLocked(lock)
+}
+
+// loginSucceeded forgets the failures. Failing it is not fatal.
+func (s *UserService) loginSucceeded(ctx context.Context, userID int) {
+	if err := s.ResetFailedLogins(ctx, userID); err != nil {
+		log.Printf("failed to reset failed logins of user %d: %v", userID, err)
+	}
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/storage/postgresql"
+	"gohelp/util"
+	"net/url"
+	"time"
+)
+
+const linkIdentityTTL = 10 * time.Minute
+
+// legacyProvider is the provider accounts were created by before
+// identities were stored. Those accounts have no password and nothing
+// linked yet.
+const legacyProvider = "google"
+
+var errInvalidLinkToken = apperr.Validation("invalid, expired or already used link")
+
+// OAuthLogin signs the user in with a provider account. Accounts are found
+// by the id at the provider and never by email alone: an unknown provider
+// account whose email belongs to an existing user is refused, the owner has
+// to sign in and link it first. Otherwise anyone who controls an account
+// with that email at any provider could take the user over.
+func (s *UserService) OAuthLogin(ctx context.Context, external models.ExternalIdentity) (*models.TokenPair, error) {
+	if external.Subject == "" {
+		return nil, apperr.Unauthorized("provider did not identify the account")
+	}
+	identity, err := s.GetIdentity(ctx, external.Provider, external.Subject)
+	if err == nil {
+		return s.identitySession(ctx, identity.UserID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error during getting identity: %v", err)
+	}
+	if external.Email == "" {
+		return nil, apperr.Validation("%s did not share an email of the account", external.Provider)
+	}
+
+	user, err := s.GetUserByEmail(ctx, external.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.oauthSignUp(ctx, external)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during getting user by email: %v", err)
+	}
+	claim, err := s.legacyAccount(ctx, user, external)
+	if err != nil {
+		return nil, err
+	}
+	if !claim {
+		return nil, apperr.Conflict("an account with this email already exists, sign in to it and link %s from the account", external.Provider)
+	}
+	if err = s.CreateIdentity(ctx, newIdentity(user.ID, external)); err != nil {
+		return nil, fmt.Errorf("error during linking identity: %v", err)
+	}
+	return s.identitySession(ctx, user.ID)
+}
+
+// legacyAccount tells if user was created by a sign in with legacyProvider
+// before identities were stored, so the provider account with the same
+// verified email is the one that created it.
+func (s *UserService) legacyAccount(ctx context.Context, user *models.User, external models.ExternalIdentity) (bool, error) {
+	if external.Provider != legacyProvider || !external.EmailVerified || user.HasPassword() {
+		return false, nil
+	}
+	identities, err := s.GetIdentities(ctx, user.ID)
+	if err != nil {
+		return false, fmt.Errorf("error during getting identities: %v", err)
+	}
+	return len(identities) == 0, nil
+}
+
+func (s *UserService) oauthSignUp(ctx context.Context, external models.ExternalIdentity) (*models.TokenPair, error) {
+	newUser := models.User{
+		Username:      util.GenerateNickname(),
+		Email:         external.Email,
+		AvatarURL:     external.AvatarURL,
+		EmailVerified: external.EmailVerified,
+	}
+	userID, err := s.CreateUserWithIdentity(ctx, newUser, newIdentity(0, external))
+	if errors.Is(err, postgresql.ErrUserExists) {
+		return nil, apperr.Conflict("an account with this email already exists, sign in to it and link %s from the account", external.Provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during creating user: %v", err)
+	}
+	if !newUser.EmailVerified {
+		s.registered(ctx, newUser.Email)
+	}
+	return s.identitySession(ctx, userID)
+}
+
+func (s *UserService) identitySession(ctx context.Context, userID int) (*models.TokenPair, error) {
+	user, err := s.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting user by id: %v", err)
+	}
+	if user.Banned {
+		return nil, s.accountBanned(ctx, user.ID)
+	}
+	return s.startSession(ctx, user)
+}
+
+func newIdentity(userID int, external models.ExternalIdentity) models.UserIdentity {
+	return models.UserIdentity{
+		UserID:   userID,
+		Provider: external.Provider,
+		Subject:  external.Subject,
+		Email:    external.Email,
+	}
+}
+
+// linkPurpose is the purpose of link tokens for the provider, so that a
+// token only works with the provider it was issued for.
+func linkPurpose(provider string) string {
+	return models.TokenLinkIdentity + ":" + provider
+}
+
+// StartLink returns the URL the signed in user has to open to link an
+// account of the provider, and the single-use token the browser of the
+// user keeps in a cookie while the provider redirects it around. The token
+// is not in the URL, so a link can't be handed to someone else.
+func (s *UserService) StartLink(ctx context.Context, userID int, provider string) (string, string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	err = s.CreateUserToken(ctx, models.UserToken{
+		UserID:    userID,
+		Purpose:   linkPurpose(provider),
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(linkIdentityTTL),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("error during saving token: %v", err)
+	}
+	return s.baseURL + "/auth/" + url.PathEscape(provider) + "?link=true", token, nil
+}
+
+// LinkIdentity links the provider account to the user the link token was
+// issued to. A token issued for another provider is refused.
+func (s *UserService) LinkIdentity(ctx context.Context, linkToken string, external models.ExternalIdentity) (*models.UserIdentity, error) {
+	userID, err := s.ConsumeUserToken(ctx, linkPurpose(external.Provider), hashToken(linkToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errInvalidLinkToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during checking token: %v", err)
+	}
+	if external.Subject == "" {
+		return nil, apperr.Unauthorized("provider did not identify the account")
+	}
+	identity, err := s.GetIdentity(ctx, external.Provider, external.Subject)
+	if err == nil {
+		if identity.UserID != userID {
+			return nil, apperr.Conflict("this %s account is linked to another user", external.Provider)
+		}
+		return identity, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error during getting identity: %v", err)
+	}
+	linked := newIdentity(userID, external)
+	err = s.CreateIdentity(ctx, linked)
+	if errors.Is(err, postgresql.ErrIdentityExists) {
+		return nil, apperr.Conflict("another %s account is already linked, unlink it first", external.Provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during linking identity: %v", err)
+	}
+	linked.CreatedAt = time.Now()
+	return &linked, nil
+}
+
+// Identities lists the provider accounts linked to the user.
+func (s *UserService) Identities(ctx context.Context, userID int) ([]models.UserIdentity, error) {
+	identities, err := s.GetIdentities(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting identities: %v", err)
+	}
+	return identities, nil
+}
+
+// UnlinkIdentity removes the provider from the user. The last way to sign
+// in can't be removed.
+func (s *UserService) UnlinkIdentity(ctx context.Context, userID int, provider string) error {
+	user, err := s.GetUserById(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error during getting user by id: %v", err)
+	}
+	identities, err := s.GetIdentities(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error during getting identities: %v", err)
+	}
+	if !user.HasPassword() && len(identities) == 1 && identities[0].Provider == provider {
+		return apperr.Conflict("this is the only way to sign in, set a password with password reset first")
+	}
+	err = s.DeleteIdentity(ctx, userID, provider)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperr.NotFound("no %s account is linked", provider)
+	}
+	return err
+}
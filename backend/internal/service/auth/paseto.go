@@ -2,8 +2,6 @@ package auth
 
 import (
 	"fmt"
-	"log"
-	"os"
 	"time"
 
 	"github.com/o1egl/paseto"
@@ -11,29 +9,72 @@ import (
 
 var pasetoInstance = paseto.NewV2()
 
+// accessTokenTTL is kept short, a client gets a new access token with its
+// refresh token.
+const accessTokenTTL = 15 * time.Minute
+
 type TokenPayload struct {
 	UserID     int       `json:"user_id"`
 	Role       string    `json:"user_role"`
+	Version    int       `json:"token_version"`
 	Expiration time.Time `json:"expiration"`
+	// EmailVerified is filled from the storage by Authenticate, it is not
+	// part of the token.
+	EmailVerified bool `json:"-"`
+}
+
+// tokenFooter is stored unencrypted in the token and tells which key of
+// the keyring the token is sealed with.
+type tokenFooter struct {
+	KeyID string `json:"kid"`
 }
 
-func GeneratePasetoToken(userID int, userRole string) (string, error) {
-	symmetricKey := []byte(os.Getenv("SYMMETRIC_KEY"))
+// GeneratePasetoToken issues an access token with the active key. It stays
+// valid only while the token version of the user equals version.
+func (k *Keyring) GeneratePasetoToken(userID int, userRole string, version int) (string, time.Time, error) {
 	payload := TokenPayload{
 		UserID:     userID,
 		Role:       userRole,
-		Expiration: time.Now().Add(24 * time.Hour),
+		Version:    version,
+		Expiration: time.Now().Add(accessTokenTTL),
+	}
+	footer := tokenFooter{KeyID: k.active.id}
+
+	var token string
+	var err error
+	if k.active.purpose == PurposePublic {
+		token, err = pasetoInstance.Sign(k.active.private, payload, footer)
+	} else {
+		token, err = pasetoInstance.Encrypt(k.active.symmetric, payload, footer)
 	}
-	
-	encrypted, err := pasetoInstance.Encrypt(symmetricKey, payload, nil)
-	return encrypted, err
+	return token, payload.Expiration, err
 }
 
-func ValidatePasetoToken(tokenString string) (*TokenPayload, error) {
-	symmetricKey := []byte(os.Getenv("SYMMETRIC_KEY"))
+// ValidatePasetoToken opens the token with the key named in its footer.
+// Keys that are retired are not accepted anymore.
+func (k *Keyring) ValidatePasetoToken(tokenString string) (*TokenPayload, error) {
+	var footer tokenFooter
+	if err := paseto.ParseFooter(tokenString, &footer); err != nil {
+		return nil, err
+	}
+	key, ok := k.keys[footer.KeyID]
+	if !ok || key.retired(time.Now()) {
+		return nil, fmt.Errorf("unknown key %q", footer.KeyID)
+	}
+	version, purpose, err := paseto.GetTokenInfo(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if version != paseto.Version2 || purpose != key.pasetoPurpose() {
+		return nil, fmt.Errorf("token does not match key %q", key.id)
+	}
+
 	var payload TokenPayload
-	var footer string
-	err := pasetoInstance.Decrypt(tokenString, symmetricKey, &payload, &footer)
+	if key.purpose == PurposePublic {
+		err = pasetoInstance.Verify(tokenString, key.public, &payload, nil)
+	} else {
+		err = pasetoInstance.Decrypt(tokenString, key.symmetric, &payload, nil)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +83,12 @@ func ValidatePasetoToken(tokenString string) (*TokenPayload, error) {
 		return nil, fmt.Errorf("token expired")
 	}
 
-	log.Println(payload)
 	return &payload, nil
 }
+
+func (k *pasetoKey) pasetoPurpose() paseto.Purpose {
+	if k.purpose == PurposePublic {
+		return paseto.PUBLIC
+	}
+	return paseto.LOCAL
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher hashes passwords with one algorithm and checks hashes
+// made by it.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	// Owns tells if the hash was made by this algorithm.
+	Owns(hash string) bool
+	// NeedsRehash tells if a hash of this algorithm was made with weaker
+	// parameters than the current ones.
+	NeedsRehash(hash string) bool
+}
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hash), err
+}
+
+func (h BcryptHasher) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h BcryptHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (h BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < h.Cost
+}
+
+// Argon2idHasher stores hashes in the PHC string format,
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>.
+type Argon2idHasher struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+var DefaultArgon2id = Argon2idHasher{Time: 1, Memory: 64 * 1024, Threads: 4, KeyLen: 32, SaltLen: 16}
+
+const argon2idPrefix = "$argon2id$"
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// decode splits the hash into the parameters it was made with, its salt
+// and its key.
+func (h Argon2idHasher) decode(hash string) (Argon2idHasher, []byte, []byte, error) {
+	var params Argon2idHasher
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errors.New("malformed argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("unsupported argon2id version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, errors.New("malformed argon2id parameters")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	params.SaltLen = uint32(len(salt))
+	params.KeyLen = uint32(len(key))
+	return params, salt, key, nil
+}
+
+func (h Argon2idHasher) Verify(hash, password string) (bool, error) {
+	params, salt, key, err := h.decode(hash)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, params.KeyLen)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h Argon2idHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, argon2idPrefix)
+}
+
+func (h Argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, _, err := h.decode(hash)
+	return err != nil || params.Time < h.Time || params.Memory < h.Memory ||
+		params.Threads < h.Threads || params.KeyLen < h.KeyLen || params.SaltLen < h.SaltLen
+}
+
+// Passwords hashes new passwords with the current algorithm and checks
+// hashes of every algorithm it knows, so users can be moved to the
+// current one when they log in.
+type Passwords struct {
+	current PasswordHasher
+	known   []PasswordHasher
+}
+
+func NewPasswords(current PasswordHasher, others ...PasswordHasher) *Passwords {
+	return &Passwords{current: current, known: append([]PasswordHasher{current}, others...)}
+}
+
+// PasswordsFromEnv uses the algorithm in PASSWORD_HASH, argon2id unless it
+// is "bcrypt".
+func PasswordsFromEnv() *Passwords {
+	bcryptHasher := BcryptHasher{Cost: bcrypt.DefaultCost}
+	if os.Getenv("PASSWORD_HASH") == "bcrypt" {
+		return NewPasswords(bcryptHasher, DefaultArgon2id)
+	}
+	return NewPasswords(DefaultArgon2id, bcryptHasher)
+}
+
+func (p *Passwords) Hash(password string) (string, error) {
+	return p.current.Hash(password)
+}
+
+// Verify checks the password and tells if its hash should be replaced by
+// a new one from Hash.
+func (p *Passwords) Verify(hash, password string) (ok, rehash bool, err error) {
+	for _, h := range p.known {
+		if !h.Owns(hash) {
+			continue
+		}
+		ok, err = h.Verify(hash, password)
+		if !ok || err != nil {
+			return false, false, err
+		}
+		return true, h != p.current || h.NeedsRehash(hash), nil
+	}
+	return false, false, errors.New("unknown password hash algorithm")
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/service/rbac"
+	"log"
+	"time"
+)
+
+// accountBanned builds the error shown to a suspended user, with the
+// reason and the end of the suspension.
+func (s *UserService) accountBanned(ctx context.Context, userID int) error {
+	sanction, err := s.GetActiveSanction(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("failed to get sanction of user %d: %v", userID, err)
+		}
+		return apperr.Banned("your account is suspended")
+	}
+	details := map[string]interface{}{
+		"reason":     sanction.Reason,
+		"expires_at": sanction.ExpiresAt,
+	}
+	if sanction.ExpiresAt == nil {
+		return &apperr.Error{Kind: apperr.ErrBanned, Message: "your account is banned", Details: details}
+	}
+	message := fmt.Sprintf("your account is suspended until %s", sanction.ExpiresAt.UTC().Format(time.RFC3339))
+	return &apperr.Error{Kind: apperr.ErrBanned, Message: message, Details: details}
+}
+
+// sanctionTarget loads the user the actor wants to sanction. Only users of
+// a lower role than the one of the actor can be sanctioned.
+func (s *UserService) sanctionTarget(ctx context.Context, actorRole string, userID int) (*models.User, error) {
+	user, err := s.GetUserById(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.NotFound("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during getting user by id: %v", err)
+	}
+	if !rbac.Outranks(actorRole, user.Role) {
+		return nil, apperr.Forbidden("you can't do this to a user with role %s", user.Role)
+	}
+	return user, nil
+}
+
+// Suspend records the sanction of the user. Ending their sessions and
+// wiping the content is up to the caller, the sanction only records that
+// the wipe was asked for.
+func (s *UserService) Suspend(ctx context.Context, actorID int, actorRole string, userID int, request models.SanctionRequest) (*models.Sanction, error) {
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return nil, apperr.Validation("expires_at must be in the future")
+	}
+	if _, err := s.sanctionTarget(ctx, actorRole, userID); err != nil {
+		return nil, err
+	}
+	sanction, err := s.CreateSanction(ctx, models.Sanction{
+		UserID:       userID,
+		Reason:       request.Reason,
+		IssuedBy:     &actorID,
+		ExpiresAt:    request.ExpiresAt,
+		ContentWiped: request.WipeContent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error during saving sanction: %v", err)
+	}
+	sanction.State = sanction.StateAt(time.Now())
+	return sanction, nil
+}
+
+// LiftSanctions ends every active sanction of the user before it expires.
+func (s *UserService) LiftSanctions(ctx context.Context, actorID int, actorRole string, userID int, reason string) error {
+	if _, err := s.sanctionTarget(ctx, actorRole, userID); err != nil {
+		return err
+	}
+	if _, err := s.UserRepo.LiftSanctions(ctx, userID, actorID, reason); err != nil {
+		return fmt.Errorf("error during lifting sanctions: %v", err)
+	}
+	return nil
+}
+
+// Sanctions returns the sanction history of the user.
+func (s *UserService) Sanctions(ctx context.Context, userID int) ([]models.Sanction, error) {
+	if _, err := s.GetUserById(ctx, userID); errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.NotFound("user not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("error during getting user by id: %v", err)
+	}
+	sanctions, err := s.GetSanctions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting sanctions: %v", err)
+	}
+	now := time.Now()
+	for i := range sanctions {
+		sanctions[i].State = sanctions[i].StateAt(now)
+	}
+	return sanctions, nil
+}
+
+// RestorableSanctions returns the sanctions of the user whose wiped content
+// can be brought back. Content of active sanctions stays wiped.
+func (s *UserService) RestorableSanctions(ctx context.Context, userID int) ([]int64, error) {
+	ids, err := s.GetWipedSanctions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting sanctions: %v", err)
+	}
+	return ids, nil
+}
+
+// ContentRestored records that content wiped by the sanctions is back.
+func (s *UserService) ContentRestored(ctx context.Context, sanctionIDs []int64) error {
+	if len(sanctionIDs) == 0 {
+		return nil
+	}
+	if err := s.SetContentRestored(ctx, sanctionIDs); err != nil {
+		return fmt.Errorf("error during updating sanctions: %v", err)
+	}
+	return nil
+}
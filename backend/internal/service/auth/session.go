@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/storage/postgresql"
+	"log"
+	"time"
+)
+
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	errInvalidRefreshToken = apperr.Unauthorized("invalid or expired refresh token")
+	errInvalidAccessToken  = apperr.Unauthorized("invalid or expired token")
+)
+
+// randomToken returns a random url safe string with n bytes of entropy.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken makes the next token of the session, the returned string
+// is the token itself and is never stored.
+func newRefreshToken(userID int, sessionID string) (string, models.RefreshToken, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", models.RefreshToken{}, err
+	}
+	return token, models.RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}, nil
+}
+
+func (s *UserService) tokenPair(user *models.User, refresh string, stored models.RefreshToken) (*models.TokenPair, error) {
+	access, expiresAt, err := s.keys.GeneratePasetoToken(user.ID, user.Role, user.TokenVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error during generating token: %v", err)
+	}
+	return &models.TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  expiresAt,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// startSession logs the user in on a new session.
+func (s *UserService) startSession(ctx context.Context, user *models.User) (*models.TokenPair, error) {
+	sessionID, err := randomToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("error during creating session: %v", err)
+	}
+	refresh, stored, err := newRefreshToken(user.ID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error during creating session: %v", err)
+	}
+	if err = s.CreateRefreshToken(ctx, stored); err != nil {
+		return nil, fmt.Errorf("error during creating session: %v", err)
+	}
+	return s.tokenPair(user, refresh, stored)
+}
+
+// RefreshSession exchanges the refresh token for a new pair of tokens. A
+// refresh token works only once: presenting a used one again means that it
+// leaked, so the whole session is revoked.
+func (s *UserService) RefreshSession(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	current, err := s.GetRefreshToken(ctx, hashToken(refreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during getting refresh token: %v", err)
+	}
+	if current.RevokedAt != nil {
+		log.Printf("reuse of refresh token of session %s, revoking it", current.SessionID)
+		if err = s.RevokeSession(ctx, current.SessionID); err != nil {
+			return nil, fmt.Errorf("error during revoking session: %v", err)
+		}
+		return nil, errInvalidRefreshToken
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, errInvalidRefreshToken
+	}
+
+	user, err := s.GetUserById(ctx, current.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting user by id: %v", err)
+	}
+	if user.Banned {
+		return nil, s.accountBanned(ctx, user.ID)
+	}
+
+	refresh, next, err := newRefreshToken(user.ID, current.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error during refreshing session: %v", err)
+	}
+	err = s.RotateRefreshToken(ctx, current.ID, next)
+	if errors.Is(err, postgresql.ErrTokenRevoked) {
+		if err = s.RevokeSession(ctx, current.SessionID); err != nil {
+			return nil, fmt.Errorf("error during revoking session: %v", err)
+		}
+		return nil, errInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during refreshing session: %v", err)
+	}
+	return s.tokenPair(user, refresh, next)
+}
+
+// Logout ends the session of the refresh token. Access tokens already
+// issued to it expire on their own shortly.
+func (s *UserService) Logout(ctx context.Context, refreshToken string) error {
+	current, err := s.GetRefreshToken(ctx, hashToken(refreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return errInvalidRefreshToken
+	}
+	if err != nil {
+		return fmt.Errorf("error during getting refresh token: %v", err)
+	}
+	if err = s.RevokeSession(ctx, current.SessionID); err != nil {
+		return fmt.Errorf("error during revoking session: %v", err)
+	}
+	return nil
+}
+
+// LogoutAll ends every session of the user and invalidates all access
+// tokens issued to them right away.
+func (s *UserService) LogoutAll(ctx context.Context, userID int) error {
+	if err := s.RevokeAllSessions(ctx, userID); err != nil {
+		return fmt.Errorf("error during revoking sessions: %v", err)
+	}
+	return nil
+}
+
+// Authenticate checks the access token and that it was not revoked since
+// it was issued. The role is taken from the storage, so its changes apply
+// at once as well.
+func (s *UserService) Authenticate(ctx context.Context, accessToken string) (*TokenPayload, error) {
+	payload, err := s.keys.ValidatePasetoToken(accessToken)
+	if err != nil {
+		return nil, errInvalidAccessToken
+	}
+	user, err := s.GetUserById(ctx, payload.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errInvalidAccessToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during getting user by id: %v", err)
+	}
+	if user.Banned {
+		return nil, s.accountBanned(ctx, user.ID)
+	}
+	if user.TokenVersion != payload.Version {
+		return nil, apperr.Unauthorized("token was revoked")
+	}
+	payload.Role = user.Role
+	payload.EmailVerified = user.EmailVerified
+	return payload, nil
+}
@@ -2,114 +2,158 @@ package auth
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/service/rbac"
 	"gohelp/internal/storage/postgresql"
-	"gohelp/util"
-
-	"github.com/markbates/goth"
-	"golang.org/x/crypto/bcrypt"
+	"gohelp/pkg/mailer"
+	"log"
+	"time"
 )
 
 type UserRepo interface {
 	CreateUser(ctx context.Context, user models.User) error
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserById(ctx context.Context, userID int) (*models.User, error)
-	ChangeBanStatus(ctx context.Context, userID int, status bool) error
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldID int64, next models.RefreshToken) error
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID int) error
+	CreateUserToken(ctx context.Context, token models.UserToken) error
+	ConsumeUserToken(ctx context.Context, purpose, tokenHash string) (int, error)
+	DiscardUserTokens(ctx context.Context, userID int, purpose string) error
+	SetEmailVerified(ctx context.Context, userID int) error
+	SetPassword(ctx context.Context, userID int, passwordHash string) error
+	GetIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	GetIdentities(ctx context.Context, userID int) ([]models.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity models.UserIdentity) error
+	CreateUserWithIdentity(ctx context.Context, user models.User, identity models.UserIdentity) (int, error)
+	DeleteIdentity(ctx context.Context, userID int, provider string) error
+	SetRole(ctx context.Context, userID int, role string) error
+	CreateSanction(ctx context.Context, sanction models.Sanction) (*models.Sanction, error)
+	GetSanctions(ctx context.Context, userID int) ([]models.Sanction, error)
+	GetActiveSanction(ctx context.Context, userID int) (*models.Sanction, error)
+	LiftSanctions(ctx context.Context, userID, liftedBy int, reason string) (int64, error)
+	GetWipedSanctions(ctx context.Context, userID int) ([]int64, error)
+	SetContentRestored(ctx context.Context, sanctionIDs []int64) error
+	LoginLockedUntil(ctx context.Context, userID int) (time.Time, error)
+	RecordFailedLogin(ctx context.Context, userID int, forgetAfter time.Duration) (int, error)
+	LockLogin(ctx context.Context, userID int, until time.Time) error
+	ResetFailedLogins(ctx context.Context, userID int) error
 }
 
 type UserService struct {
 	UserRepo
+	keys      *Keyring
+	passwords *Passwords
+	mail      mailer.Mailer
+	// baseURL is where links in emails point to.
+	baseURL string
+}
+
+func NewUserService(userRepo *postgresql.UserRepository, keys *Keyring, passwords *Passwords, mail mailer.Mailer, baseURL string) *UserService {
+	return &UserService{UserRepo: userRepo, keys: keys, passwords: passwords, mail: mail, baseURL: baseURL}
 }
 
-func NewUserService(userRepo *postgresql.UserRepository) *UserService {
-	return &UserService{UserRepo: userRepo}
+// PublicKeys returns the keys other services can verify access tokens with.
+func (s *UserService) PublicKeys() []PublicKey {
+	return s.keys.PublicKeys()
 }
 
 func (s *UserService) RegisterUser(ctx context.Context, user models.SignUp) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwords.Hash(user.Password)
 	if err != nil {
 		return err
 	}
 	newUser := models.User{
 		Username:     user.Username,
 		Email:        user.Email,
-		Password:     user.Password,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 	}
 	err = s.CreateUser(ctx, newUser)
+	if errors.Is(err, postgresql.ErrUserExists) {
+		return apperr.Conflict("user with this username or email already exists")
+	}
 	if err != nil {
 		return fmt.Errorf("error during creating user:%v", err)
 	}
+	s.registered(ctx, newUser.Email)
 	return nil
 }
 
-func (s *UserService) LoginUser(ctx context.Context, email, password string) (string, error) {
+func (s *UserService) LoginUser(ctx context.Context, email, password string) (*models.TokenPair, error) {
 	user, err := s.GetUserByEmail(ctx, email)
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, errInvalidCredentials
 	}
 
-	if user.Banned {
-		return "", errors.New("your account is banned")
+	// Accounts without a password log in only through their provider.
+	if !user.HasPassword() {
+		return nil, errInvalidCredentials
 	}
-
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err = s.checkLoginLock(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	ok, rehash, err := s.passwords.Verify(user.PasswordHash, password)
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, fmt.Errorf("error during checking password: %v", err)
+	}
+	if !ok {
+		return nil, s.loginFailed(ctx, user.ID)
+	}
+	s.loginSucceeded(ctx, user.ID)
+	// The reason and end of a sanction are shown only to whoever knows
+	// the password.
+	if user.Banned {
+		return nil, s.accountBanned(ctx, user.ID)
 	}
+	if rehash {
+		s.rehashPassword(ctx, user.ID, password)
+	}
+
+	return s.startSession(ctx, user)
+}
 
-	token, err := GeneratePasetoToken(user.ID, user.Role)
+// rehashPassword moves the user to the current hashing algorithm. It runs
+// on login, when the password is known, and failing it is not fatal.
+func (s *UserService) rehashPassword(ctx context.Context, userID int, password string) {
+	hash, err := s.passwords.Hash(password)
+	if err == nil {
+		err = s.SetPassword(ctx, userID, hash)
+	}
 	if err != nil {
-		return "", fmt.Errorf("error during generating token: %v", err)
+		log.Printf("failed to rehash password of user %d: %v", userID, err)
 	}
-
-	return token, nil
 }
 
-func (s *UserService) UsersActions(ctx context.Context, userID int, action string) (*models.User, error) {
+// AssignRole gives the user a new role. It applies to the next request of
+// the user, as the role is read from the storage on every request.
+// Administrators can't change their own role, so that the last one can't
+// lock everybody out.
+func (s *UserService) AssignRole(ctx context.Context, actorID, userID int, role string) (*models.User, error) {
+	if !rbac.Valid(role) {
+		return nil, apperr.Validation("unknown role %q", role)
+	}
+	if actorID == userID {
+		return nil, apperr.Forbidden("you can't change your own role")
+	}
 	user, err := s.GetUserById(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.NotFound("user not found")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error during getting user by id: %v", err)
 	}
-	var status bool
-	if action == "ban" {
-		status = true
-	} else {
-		status = false
+	if user.Role == role {
+		return user, nil
 	}
-
-	err = s.ChangeBanStatus(ctx, userID, status)
-	if err != nil {
-		return nil, err
+	if err = s.SetRole(ctx, userID, role); err != nil {
+		return nil, fmt.Errorf("error during setting role: %v", err)
 	}
-
+	user.Role = role
 	return user, nil
 }
-
-func (s *UserService) GoogleAuth(ctx context.Context, googleUser goth.User) (string, error){
-	user, err := s.GetUserByEmail(ctx, googleUser.Email)
-	if user.Banned{
-		return "", errors.New("your account is banned")
-	}
-	if err !=  nil{
-		newUser := models.User{
-			Username:     util.GenerateNickname(),
-			Email:        googleUser.Email,
-			Password:     " ",
-			PasswordHash: " ",
-		}
-		s.CreateUser(ctx, newUser)
-		user, err = s.GetUserByEmail(ctx, googleUser.Email)
-		if err != nil{
-			return "", err
-		}
-	}
-	token, err:= GeneratePasetoToken(user.ID, user.Role)
-	if err != nil {
-		return "", fmt.Errorf("error during generating token: %v", err)
-	}
-	return token, nil
-
-}
@@ -0,0 +1,63 @@
+package forum
+
+import (
+	"context"
+	"fmt"
+	"gohelp/internal/models"
+)
+
+// authorSet collects the ids of authors of a response and, once all of
+// them are known, loads them with a single query.
+type authorSet map[int]*models.Author
+
+func (a authorSet) add(id int) {
+	if _, ok := a[id]; !ok {
+		a[id] = nil
+	}
+}
+
+func (s *ForumService) loadAuthors(ctx context.Context, set authorSet) error {
+	if len(set) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	authors, err := s.users.GetAuthors(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("error during getting authors: %v", err)
+	}
+	for i := range authors {
+		set[authors[i].ID] = &authors[i]
+	}
+	return nil
+}
+
+func (s *ForumService) attachCommentAuthors(ctx context.Context, comments []models.Comment) error {
+	set := authorSet{}
+	for _, comment := range comments {
+		set.add(comment.AuthorID)
+	}
+	if err := s.loadAuthors(ctx, set); err != nil {
+		return err
+	}
+	for i := range comments {
+		comments[i].Author = set[comments[i].AuthorID]
+	}
+	return nil
+}
+
+func (s *ForumService) attachTopicAuthors(ctx context.Context, discussions []models.DiscussionTopic) error {
+	set := authorSet{}
+	for _, discussion := range discussions {
+		set.add(discussion.AuthorID)
+	}
+	if err := s.loadAuthors(ctx, set); err != nil {
+		return err
+	}
+	for i := range discussions {
+		discussions[i].Author = set[discussions[i].AuthorID]
+	}
+	return nil
+}
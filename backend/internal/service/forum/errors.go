@@ -0,0 +1,31 @@
+package forum
+
+import (
+	"errors"
+	"fmt"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/storage/mongo"
+)
+
+// Kinds of errors returned by ForumService, check them with errors.Is.
+var (
+	ErrNotFound   = apperr.ErrNotFound
+	ErrForbidden  = apperr.ErrForbidden
+	ErrConflict   = apperr.ErrConflict
+	ErrValidation = apperr.ErrValidation
+)
+
+var (
+	ErrInvalidCursor = apperr.Validation("invalid cursor")
+	ErrUserNotFound  = apperr.NotFound("user not found")
+	errNoPermissions = apperr.Forbidden("you have no permissions to do this")
+)
+
+// lookupError reports err of loading what from the storage, a missing
+// document becomes ErrNotFound.
+func lookupError(err error, what string) error {
+	if errors.Is(err, mongo.ErrNotFound) {
+		return apperr.NotFound("%s not found", what)
+	}
+	return fmt.Errorf("error during getting %s: %v", what, err)
+}
@@ -0,0 +1,79 @@
+package forum
+
+import (
+	"context"
+	"gohelp/internal/models"
+	"gohelp/pkg/pubsub"
+	"log"
+)
+
+type Publisher interface {
+	Publish(topic string, event pubsub.Event)
+}
+
+// VoteCounts is the data of a votes.changed event.
+type VoteCounts struct {
+	PostType string `json:"post_type"`
+	ID       string `json:"id"`
+	Likes    int    `json:"likes"`
+	Dislikes int    `json:"dislikes"`
+}
+
+// CommentRef is the data of a comment.deleted event.
+type CommentRef struct {
+	ID           string `json:"id"`
+	DiscussionID string `json:"discussion_id"`
+}
+
+func (s *ForumService) publish(discussionID, eventType string, data interface{}) {
+	s.events.Publish(pubsub.DiscussionTopic(discussionID), pubsub.Event{Type: eventType, Data: data})
+}
+
+// StreamableDiscussion returns a not found error unless the discussion
+// exists, so that clients do not wait on events that never come.
+func (s *ForumService) StreamableDiscussion(ctx context.Context, discussionID string) error {
+	if _, err := s.repo.GetDiscussion(ctx, discussionID); err != nil {
+		return lookupError(err, "discussion")
+	}
+	return nil
+}
+
+// publishComment sends the current state of the comment with its author.
+// Held and deleted comments are not sent. The change is stored already,
+// so failing is only logged.
+func (s *ForumService) publishComment(ctx context.Context, eventType, commentID string) {
+	comment, err := s.repo.GetComment(ctx, commentID)
+	if err == nil && comment.Deleted {
+		return
+	}
+	if err == nil {
+		comments := []models.Comment{*comment}
+		if err = s.attachCommentAuthors(ctx, comments); err == nil {
+			s.publish(comment.DiscussionID, eventType, comments[0])
+			return
+		}
+	}
+	log.Printf("failed to publish %s of comment %s: %v", eventType, commentID, err)
+}
+
+// publishVotes sends the vote counts of the post after a vote.
+func (s *ForumService) publishVotes(ctx context.Context, postType, postID string) {
+	counts := VoteCounts{PostType: postType, ID: postID}
+	var discussionID string
+	if postType == models.PostDiscussion {
+		disc, err := s.repo.GetDiscussion(ctx, postID)
+		if err != nil {
+			log.Printf("failed to publish votes of discussion %s: %v", postID, err)
+			return
+		}
+		discussionID, counts.Likes, counts.Dislikes = disc.ID, disc.LikesCount, disc.DisikesCount
+	} else {
+		comm, err := s.repo.GetComment(ctx, postID)
+		if err != nil {
+			log.Printf("failed to publish votes of comment %s: %v", postID, err)
+			return
+		}
+		discussionID, counts.Likes, counts.Dislikes = comm.DiscussionID, comm.LikesCount, comm.DisikesCount
+	}
+	s.publish(discussionID, pubsub.VotesChanged, counts)
+}
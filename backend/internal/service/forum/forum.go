@@ -5,67 +5,191 @@ import (
 	"errors"
 	"fmt"
 	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/service/notification"
+	"gohelp/internal/service/rbac"
+	"gohelp/internal/service/screening"
 	"gohelp/internal/storage/mongo"
+	"gohelp/internal/storage/postgresql"
+	"gohelp/pkg/pubsub"
+	"gohelp/util"
 	"log"
 )
 
+type UserRepo interface {
+	GetUserById(ctx context.Context, userID int) (*models.User, error)
+	GetAuthors(ctx context.Context, ids []int) ([]models.Author, error)
+	AddReputation(ctx context.Context, deltas map[int]int) error
+	ResetReputation(ctx context.Context, scores map[int]int) error
+	GetUserIDsByUsernames(ctx context.Context, usernames []string) (map[string]int, error)
+}
+
 type ForumService struct {
-	repo *mongo.ForumStorage
+	repo          *mongo.ForumStorage
+	users         UserRepo
+	subscriptions SubscriptionRepo
+	screening     *screening.Pipeline
+	notifier      Notifier
+	events        Publisher
+}
+
+func NewForumService(repo *mongo.ForumStorage, users *postgresql.UserRepository, subscriptions *postgresql.SubscriptionRepository,
+	screening *screening.Pipeline, notifier *notification.NotificationService, events *pubsub.Hub) *ForumService {
+	return &ForumService{repo: repo, users: users, subscriptions: subscriptions, screening: screening, notifier: notifier, events: events}
+}
+
+// screen runs the screening on a new post of the author. Posts of users
+// who review held posts themselves are not screened.
+func (s *ForumService) screen(ctx context.Context, postType, title, content string, authorID int) (*models.Screening, error) {
+	author, err := s.users.GetUserById(ctx, authorID)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting user by id: %v", err)
+	}
+	if rbac.Can(author.Role, rbac.PostReview) {
+		return nil, nil
+	}
+	verdict, err := s.screening.Screen(ctx, screening.Post{
+		Type:       postType,
+		Title:      title,
+		Content:    content,
+		AuthorID:   authorID,
+		Reputation: author.Reputation,
+		JoinedAt:   author.CreatedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error during screening: %v", err)
+	}
+	return verdict, nil
+}
+
+// published announces a new post unless the screening held it, held posts
+// are announced once approved. The post is stored already, so failing is
+// only logged.
+func (s *ForumService) published(ctx context.Context, postType, postID string, verdict *models.Screening) {
+	if verdict != nil {
+		return
+	}
+	if err := s.AnnouncePost(ctx, postType, postID); err != nil {
+		log.Printf("failed to announce %s %s: %v", postType, postID, err)
+	}
 }
 
-func NewForumService(repo *mongo.ForumStorage) *ForumService {
-	return &ForumService{repo: repo}
+// editedComment tells the clients streaming the discussion about an edit
+// of the comment. A held comment is gone for them until it is approved.
+func (s *ForumService) editedComment(ctx context.Context, commentID, discussionID string, verdict *models.Screening) {
+	if verdict != nil {
+		s.publish(discussionID, pubsub.CommentDeleted, CommentRef{ID: commentID, DiscussionID: discussionID})
+		return
+	}
+	s.publishComment(ctx, pubsub.CommentEdited, commentID)
 }
 
-func (s *ForumService) CreateDiscussion(ctx context.Context, title, content string, authorID int) (string, error) {
+// postStatus tells the author whether the post is visible yet.
+func postStatus(verdict *models.Screening) string {
+	if verdict != nil {
+		return models.PostHeld
+	}
+	return models.PostPublished
+}
+
+// CreateDiscussion stores the discussion and returns its id and status.
+// Discussions the screening flags are held for review. The author follows
+// the discussion from now on.
+func (s *ForumService) CreateDiscussion(ctx context.Context, title, content string, tags []string, authorID int) (string, string, error) {
+	tags, err := s.checkTags(ctx, tags)
+	if err != nil {
+		return "", "", err
+	}
+	verdict, err := s.screen(ctx, models.PostDiscussion, title, content, authorID)
+	if err != nil {
+		return "", "", err
+	}
 	discussion := &models.Discussion{
-		Title:    title,
-		Content:  content,
-		AuthorID: authorID,
+		Title:     title,
+		Content:   content,
+		Tags:      tags,
+		AuthorID:  authorID,
+		Screening: verdict,
+	}
+	id, err := s.repo.CreateDiscussion(ctx, discussion)
+	if err != nil {
+		return "", "", err
 	}
-	return s.repo.CreateDiscussion(ctx, discussion)
+	s.subscribeAuthor(ctx, authorID, id)
+	s.published(ctx, models.PostDiscussion, id, verdict)
+	return id, postStatus(verdict), nil
 }
 
-func (s *ForumService) CreateComment(ctx context.Context, related_to, discussionID, content string, authorID int) (string, error) {
+// CreateComment stores the comment and returns its id and status. Comments
+// the screening flags are held for review.
+func (s *ForumService) CreateComment(ctx context.Context, related_to, discussionID, content string, authorID int) (string, string, error) {
 	var err error
 	if _, err = s.repo.GetDiscussion(ctx, discussionID); err != nil {
-		return "", fmt.Errorf("error during searching related disc: %v", err)
+		return "", "", lookupError(err, "discussion")
 	}
 	if related_to != "" {
 		comment, err := s.repo.GetComment(ctx, related_to)
 		if err != nil {
-			return "", fmt.Errorf("error during searching related comment: %v", err)
+			return "", "", lookupError(err, "related comment")
 		}
 		if comment.DiscussionID != discussionID {
-			return "", fmt.Errorf("invalid related comment: %v", err)
+			return "", "", apperr.Validation("related comment belongs to another discussion")
 		}
 	}
+	verdict, err := s.screen(ctx, models.PostComment, "", content, authorID)
+	if err != nil {
+		return "", "", err
+	}
 
 	comment := &models.Comment{
 		DiscussionID: discussionID,
 		RelatedTo:    related_to,
 		Content:      content,
 		AuthorID:     authorID,
+		Screening:    verdict,
+	}
+	id, err := s.repo.CreateComment(ctx, comment)
+	if errors.Is(err, mongo.ErrNotFound) {
+		return "", "", apperr.NotFound("discussion not found")
 	}
-	return s.repo.CreateComment(ctx, comment)
+	if err != nil {
+		return "", "", fmt.Errorf("error during creating comment: %v", err)
+	}
+	s.published(ctx, models.PostComment, id, verdict)
+	return id, postStatus(verdict), nil
 }
 
 func (s *ForumService) GetDiscussionWithComments(ctx context.Context, discussionID string) (*models.Discussion, []models.Comment, error) {
 	discussion, err := s.repo.GetDiscussion(ctx, discussionID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error during getting discussion: %v", err)
+		return nil, nil, lookupError(err, "discussion")
 	}
 
 	comments, err := s.repo.GetCommentsByDiscussion(ctx, discussionID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error during getting comments of discussions: %v", err)
 	}
-	commentsTree := buildCommentTree(comments)
+
+	authors := authorSet{}
+	authors.add(discussion.AuthorID)
+	for _, comment := range comments {
+		authors.add(comment.AuthorID)
+	}
+	if err = s.loadAuthors(ctx, authors); err != nil {
+		return nil, nil, err
+	}
+	discussion.Author = authors[discussion.AuthorID]
+	for i := range comments {
+		comments[i].Author = authors[comments[i].AuthorID]
+	}
+	commentsTree := buildCommentTree(comments, discussion.AcceptedAnswerID)
 
 	return discussion, commentsTree, nil
 }
 
-func buildCommentTree(comments []models.Comment) []models.Comment {
+// buildCommentTree nests comments under the comment they relate to. The
+// accepted answer, if any, is pinned first among the top-level comments.
+func buildCommentTree(comments []models.Comment, acceptedID string) []models.Comment {
 
 	tree := make(map[string][]models.Comment)
 	var rootComments []models.Comment
@@ -93,26 +217,68 @@ func buildCommentTree(comments []models.Comment) []models.Comment {
 	}
 
 	rootComments = addChildren("")
+	if acceptedID != "" {
+		for i := range rootComments {
+			if rootComments[i].ID == acceptedID {
+				accepted := rootComments[i]
+				accepted.Accepted = true
+				copy(rootComments[1:i+1], rootComments[:i])
+				rootComments[0] = accepted
+				break
+			}
+		}
+	}
 	return rootComments
 }
-func (s *ForumService) GetAllDiscussionsWithCountOfComments(ctx context.Context) ([]models.DiscussionWithCount, error) {
-	discussions, err := s.repo.GetAllDiscussions(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error during getting list of discussions: %v", err)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func (s *ForumService) GetAllDiscussionsWithCountOfComments(ctx context.Context, query models.DiscussionListQuery) ([]models.DiscussionWithCount, string, error) {
+	query.Limit = pageSize(query.Limit)
+	if query.Sort == "" {
+		query.Sort = models.SortNewest
+	}
+	summary, nextCursor, err := s.repo.GetDiscussionsPage(ctx, query)
+	if errors.Is(err, mongo.ErrInvalidCursor) {
+		return nil, "", ErrInvalidCursor
 	}
-	summary, err := s.repo.GetSummaryOfDiscussions(ctx, discussions)
 	if err != nil {
-		return nil, fmt.Errorf("error during getting list of comments for discussions: %v", err)
+		return nil, "", fmt.Errorf("error during getting list of discussions: %v", err)
+	}
+
+	authors := authorSet{}
+	for _, item := range summary {
+		authors.add(item.Discussion.AuthorID)
+	}
+	if err = s.loadAuthors(ctx, authors); err != nil {
+		return nil, "", err
 	}
-	return summary, nil
+	for i := range summary {
+		summary[i].Discussion.Author = authors[summary[i].Discussion.AuthorID]
+	}
+	return summary, nextCursor, nil
 }
 
-func (s *ForumService) SearchDiscussionsByName(ctx context.Context, searchTerm string) ([]models.Discussion, error) {
+func (s *ForumService) SearchDiscussionsByName(ctx context.Context, searchTerm string, filter models.DiscussionFilter) ([]models.Discussion, error) {
 
-	discussions, err := s.repo.SearchDiscussionsByName(ctx, searchTerm)
+	discussions, err := s.repo.SearchDiscussionsByName(ctx, searchTerm, filter)
 	if err != nil {
 		return nil, fmt.Errorf("error during getting list of discussions ny name: %v", err)
 	}
+
+	authors := authorSet{}
+	for _, discussion := range discussions {
+		authors.add(discussion.AuthorID)
+	}
+	if err = s.loadAuthors(ctx, authors); err != nil {
+		return nil, err
+	}
+	for i := range discussions {
+		discussions[i].Author = authors[discussions[i].AuthorID]
+	}
 	return discussions, nil
 }
 
@@ -120,131 +286,287 @@ func (s *ForumService) Vote(ctx context.Context, userID int, element_id, voteTyp
 	_, err1 := s.repo.GetDiscussion(ctx, element_id)
 	_, err2 := s.repo.GetComment(ctx, element_id)
 	if (err1 != nil && err2 != nil) || (err1 == nil && err2 == nil) {
-		return errors.New("nothing was found or discussion with comment has equal ids")
+		return apperr.NotFound("nothing was found or discussion with comment has equal ids")
 	} else if err1 == nil {
-		err := s.VoteDiscussion(ctx, userID, element_id, voteType)
+		disc, err := s.VoteDiscussion(ctx, userID, element_id, voteType)
 		if err != nil {
 			return err
 		}
+		prev := previousVote(disc.Likes, disc.Dislikes, userID)
+		if err = s.rewardVote(ctx, disc.AuthorID, userID, prev, voteType); err != nil {
+			return err
+		}
+		s.notifyVote(ctx, disc.AuthorID, userID, prev, voteType, models.Notification{DiscussionID: disc.ID})
+		s.publishVotes(ctx, models.PostDiscussion, disc.ID)
 		return nil
 	} else if err2 == nil {
-		err := s.VoteComment(ctx, userID, element_id, voteType)
+		comm, err := s.VoteComment(ctx, userID, element_id, voteType)
 		if err != nil {
 			return err
 		}
+		prev := previousVote(comm.Likes, comm.Dislikes, userID)
+		if err = s.rewardVote(ctx, comm.AuthorID, userID, prev, voteType); err != nil {
+			return err
+		}
+		s.notifyVote(ctx, comm.AuthorID, userID, prev, voteType, models.Notification{DiscussionID: comm.DiscussionID, CommentID: comm.ID})
+		s.publishVotes(ctx, models.PostComment, comm.ID)
 		return nil
 	}
 	log.Println("function was ended suspicious")
 	return nil
 }
 
-func (s *ForumService) VoteDiscussion(ctx context.Context, userID int, discussionID, voteType string) error {
-
-	err := s.repo.RemoveVote(userID, discussionID, voteType)
-	if err != nil {
-		return fmt.Errorf("error during removing votes: %v", err)
+// VoteDiscussion replaces the vote of the user on the discussion. The vote
+// type "none" only withdraws the previous vote. It returns the discussion
+// as it was right before the vote, which tells the vote it replaced even
+// when the user votes twice at once.
+func (s *ForumService) VoteDiscussion(ctx context.Context, userID int, discussionID, voteType string) (*models.Discussion, error) {
+	before, err := s.repo.VoteDiscussion(ctx, discussionID, userID, voteType)
+	if errors.Is(err, mongo.ErrNotFound) {
+		return nil, apperr.NotFound("discussion not found")
 	}
-	err = s.repo.DiscAddVote(userID, discussionID, voteType)
 	if err != nil {
-		return fmt.Errorf("error during adding vote: %v", err)
+		return nil, fmt.Errorf("error during voting: %v", err)
 	}
-	return nil
+	return before, nil
 }
 
-func (s *ForumService) VoteComment(ctx context.Context, userID int, commentID, voteType string) error {
-
-	err := s.repo.RemoveVote(userID, commentID, voteType)
-	if err != nil {
-		return fmt.Errorf("error during removing votes: %v", err)
+// VoteComment replaces the vote of the user on the comment. The vote type
+// "none" only withdraws the previous vote. It returns the comment as it
+// was right before the vote.
+func (s *ForumService) VoteComment(ctx context.Context, userID int, commentID, voteType string) (*models.Comment, error) {
+	before, err := s.repo.VoteComment(ctx, commentID, userID, voteType)
+	if errors.Is(err, mongo.ErrNotFound) {
+		return nil, apperr.NotFound("comment not found")
 	}
-	err = s.repo.ComAddVote(userID, commentID, voteType)
 	if err != nil {
-		return fmt.Errorf("error during adding vote: %v", err)
+		return nil, fmt.Errorf("error during voting: %v", err)
 	}
-	return nil
+	return before, nil
 }
 
-func (s *ForumService) UpdateDiscussion(ctx context.Context, discussionID, content string, authorID int) (*models.Discussion, error) {
+// UpdateDiscussion replaces the content and returns the discussion with
+// its status. New content goes through the screening like a new post, a
+// flagged edit is saved and held for review.
+func (s *ForumService) UpdateDiscussion(ctx context.Context, discussionID, content string, authorID int) (*models.Discussion, string, error) {
 
 	disc, err := s.repo.GetDiscussion(ctx, discussionID)
 	if err != nil {
-		return nil, fmt.Errorf("error during getting discussion: %v", err)
+		return nil, "", lookupError(err, "discussion")
 	}
 	if disc.AuthorID != authorID {
-		return nil, errors.New("you have no permissions to do this")
+		return nil, "", errNoPermissions
+	}
+	if disc.Content == content {
+		return disc, models.PostPublished, nil
+	}
+	verdict, err := s.screen(ctx, models.PostDiscussion, disc.Title, content, authorID)
+	if err != nil {
+		return nil, "", err
 	}
-	err = s.repo.UpdateDiscussion(ctx, discussionID, content)
+	err = s.repo.UpdateDiscussion(ctx, discussionID, content, authorID, verdict)
 	if err != nil {
-		return nil, fmt.Errorf("error during updating discussion: %v", err)
+		return nil, "", fmt.Errorf("error during updating discussion: %v", err)
+	}
+	if verdict != nil {
+		// The held discussion can't be read back, the author sees the edit.
+		disc.Content, disc.Edited = content, true
+		return disc, models.PostHeld, nil
 	}
 	disc, err = s.repo.GetDiscussion(ctx, discussionID)
 	if err != nil {
-		return nil, fmt.Errorf("error during getting discussion: %v", err)
+		return nil, "", lookupError(err, "discussion")
 	}
 
-	return disc, nil
+	return disc, models.PostPublished, nil
 }
-func (s *ForumService) UpdateComment(ctx context.Context, commentID, content string, authorID int) (*models.Comment, error) {
+
+// UpdateComment replaces the content and returns the comment with its
+// status. New content goes through the screening like a new post, a
+// flagged edit is saved and held for review.
+func (s *ForumService) UpdateComment(ctx context.Context, commentID, content string, authorID int) (*models.Comment, string, error) {
 
 	comm, err := s.repo.GetComment(ctx, commentID)
 	if err != nil {
-		return nil, fmt.Errorf("error during getting discussion: %v", err)
+		return nil, "", lookupError(err, "comment")
 	}
 	if comm.AuthorID != authorID {
-		return nil, errors.New("you have no permissions to do this")
+		return nil, "", errNoPermissions
+	}
+	if comm.Content == content {
+		return comm, models.PostPublished, nil
+	}
+	verdict, err := s.screen(ctx, models.PostComment, "", content, authorID)
+	if err != nil {
+		return nil, "", err
 	}
-	err = s.repo.UpdateDiscussion(ctx, commentID, content)
+	err = s.repo.UpdateComment(ctx, commentID, content, authorID, verdict)
 	if err != nil {
-		return nil, fmt.Errorf("error during updating discussion: %v", err)
+		return nil, "", fmt.Errorf("error during updating comment: %v", err)
+	}
+	s.editedComment(ctx, commentID, comm.DiscussionID, verdict)
+	if verdict != nil {
+		// The held comment can't be read back, the author sees the edit.
+		comm.Content, comm.Edited = content, true
+		return comm, models.PostHeld, nil
 	}
 	comm, err = s.repo.GetComment(ctx, commentID)
 	if err != nil {
-		return nil, fmt.Errorf("error during getting discussion: %v", err)
+		return nil, "", lookupError(err, "comment")
+	}
+	return comm, models.PostPublished, nil
+}
+
+// AcceptAnswer lets the author of the discussion mark one of its top-level
+// comments as the accepted answer. An empty commentID clears the choice.
+func (s *ForumService) AcceptAnswer(ctx context.Context, discussionID, commentID string, userID int) (*models.Discussion, error) {
+	disc, err := s.repo.GetDiscussion(ctx, discussionID)
+	if err != nil {
+		return nil, lookupError(err, "discussion")
+	}
+	if disc.AuthorID != userID {
+		return nil, apperr.Forbidden("only author of discussion can accept an answer")
+	}
+	if commentID == disc.AcceptedAnswerID {
+		return disc, nil
+	}
+	deltas := make(map[int]int)
+	var accepted *models.Comment
+	if commentID != "" {
+		comm, err := s.repo.GetComment(ctx, commentID)
+		if err != nil {
+			return nil, lookupError(err, "comment")
+		}
+		accepted = comm
+		if comm.DiscussionID != discussionID {
+			return nil, apperr.Validation("comment does not belong to this discussion")
+		}
+		if comm.RelatedTo != "" {
+			return nil, apperr.Validation("only top-level comment can be accepted as an answer")
+		}
+		if comm.AuthorID != disc.AuthorID {
+			deltas[comm.AuthorID] += models.ReputationAccepted
+		}
+	}
+	if disc.AcceptedAnswerID != "" {
+		prev, err := s.repo.GetComment(ctx, disc.AcceptedAnswerID)
+		if err == nil && prev.AuthorID != disc.AuthorID {
+			deltas[prev.AuthorID] -= models.ReputationAccepted
+		}
+	}
+	err = s.repo.SetAcceptedAnswer(ctx, discussionID, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("error during accepting answer: %v", err)
 	}
-	return comm, nil
+	if err = s.users.AddReputation(ctx, deltas); err != nil {
+		return nil, fmt.Errorf("error during updating reputation: %v", err)
+	}
+	if accepted != nil {
+		s.notify(ctx, models.Notification{
+			UserID:       accepted.AuthorID,
+			Type:         models.NotifyAccepted,
+			ActorID:      &userID,
+			DiscussionID: discussionID,
+			CommentID:    commentID,
+		})
+	}
+	disc.AcceptedAnswerID = commentID
+	disc.Resolved = commentID != ""
+	return disc, nil
 }
 
-func (s *ForumService) DeleteFullDiscussion(ctx context.Context, discussionID string) error {
+// DeleteFullDiscussion deletes the discussion with its comments. Only
+// moderators can do it, authors can't delete discussions others answered.
+func (s *ForumService) DeleteFullDiscussion(ctx context.Context, discussionID string, moderatorID int) error {
 	_, err := s.repo.GetDiscussion(ctx, discussionID)
 	if err != nil {
-		return fmt.Errorf("error during getting discussion: %v", err)
+		return lookupError(err, "discussion")
 	}
-	err = s.repo.DeleteFullDiscussion(ctx, discussionID)
+	scores, err := s.repo.ReputationOfDiscussion(ctx, discussionID)
+	if err != nil {
+		return fmt.Errorf("error during counting reputation: %v", err)
+	}
+	err = s.repo.DeleteFullDiscussion(ctx, discussionID, models.DeletedByModerator, moderatorID)
 	if err != nil {
 		return fmt.Errorf("error during updating discussion: %v", err)
 	}
 
-	return nil
+	return s.revokeReputation(ctx, scores)
 }
 func (s *ForumService) DeleteComment(ctx context.Context, commentID, userRole string, authorID int) error {
 
 	comm, err := s.repo.GetComment(ctx, commentID)
 	if err != nil {
-		return fmt.Errorf("error during getting discussion: %v", err)
+		return lookupError(err, "comment")
 	}
-	if userRole == models.CustomerRole {
-		if comm.AuthorID != authorID {
-			return errors.New("you have no permissions to do this")
+	cause := models.DeletedByAuthor
+	if comm.AuthorID != authorID {
+		if !rbac.Can(userRole, rbac.CommentDeleteAny) {
+			return errNoPermissions
 		}
+		cause = models.DeletedByModerator
 	}
-	err = s.repo.DeleteComment(ctx, commentID)
+	scores, err := s.repo.ReputationOfComment(ctx, commentID)
+	if err != nil {
+		return fmt.Errorf("error during counting reputation: %v", err)
+	}
+	err = s.repo.DeleteComment(ctx, commentID, cause, authorID)
 	if err != nil {
 		return fmt.Errorf("error during updating discussion: %v", err)
 	}
+	err = s.repo.ClearAcceptedAnswer(ctx, commentID)
+	if err != nil {
+		return fmt.Errorf("error during reopening discussion: %v", err)
+	}
+	s.publish(comm.DiscussionID, pubsub.CommentDeleted, CommentRef{ID: comm.ID, DiscussionID: comm.DiscussionID})
 
-	return nil
+	return s.revokeReputation(ctx, scores)
 }
 
-func (s *ForumService) DeleteFullHistory(ctx context.Context, userID int) error{
-	err := s.repo.DeleteAllComments(ctx, userID)
+// WipeUserContent deletes everything the user wrote, and the comments on
+// their discussions, as part of the sanction. The reputation to revoke is
+// counted once, before anything is deleted, and every step after it is
+// retried on its own, so a failed step does not lose what the earlier ones
+// did.
+func (s *ForumService) WipeUserContent(ctx context.Context, userID int, sanctionID int64) error {
+	scores, err := s.repo.ReputationOfAuthorContent(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error during counting reputation: %v", err)
+	}
+	err = util.Retry(ctx, func() error {
+		return s.repo.DeleteAllComments(ctx, userID, sanctionID)
+	})
 	if err != nil {
-		return  fmt.Errorf("error during deleting comments: %v", err)
+		return fmt.Errorf("error during deleting comments: %v", err)
 	}
 
-	err = s.repo.DeleteAllDiscussions(ctx, userID)
+	err = util.Retry(ctx, func() error {
+		return s.repo.DeleteAllDiscussions(ctx, userID, sanctionID)
+	})
 	if err != nil {
-		return  fmt.Errorf("error during deleting discussions: %v", err)
+		return fmt.Errorf("error during deleting discussions: %v", err)
 	}
-	return nil
+	return util.Retry(ctx, func() error {
+		return s.revokeReputation(ctx, scores)
+	})
+}
 
-}
\ No newline at end of file
+// RestoreUserContent brings back the content wiped by the sanctions of the
+// user together with the reputation it gave. Restored content gives its
+// reputation only once, so that step is retried on its own.
+func (s *ForumService) RestoreUserContent(ctx context.Context, userID int, sanctionIDs []int64) error {
+	if len(sanctionIDs) == 0 {
+		return nil
+	}
+	scores, err := s.repo.RestoreSanctioned(ctx, userID, sanctionIDs)
+	if err != nil {
+		return fmt.Errorf("error during restoring content: %v", err)
+	}
+	err = util.Retry(ctx, func() error {
+		return s.users.AddReputation(ctx, scores)
+	})
+	if err != nil {
+		return fmt.Errorf("error during updating reputation: %v", err)
+	}
+	return nil
+}
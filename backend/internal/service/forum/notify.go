@@ -0,0 +1,128 @@
+package forum
+
+import (
+	"context"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/pkg/pubsub"
+	"gohelp/util"
+	"log"
+	"strconv"
+)
+
+type Notifier interface {
+	Notify(ctx context.Context, notifications ...models.Notification) error
+}
+
+// notify sends the notifications. Failing is only logged, what they tell
+// about is done already.
+func (s *ForumService) notify(ctx context.Context, notifications ...models.Notification) {
+	if err := s.notifier.Notify(ctx, notifications...); err != nil {
+		log.Printf("failed to notify: %v", err)
+	}
+}
+
+// mentioned returns the ids of the users mentioned in the text.
+func (s *ForumService) mentioned(ctx context.Context, text string) ([]int, error) {
+	names := util.ParseMentions(text)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	ids, err := s.users.GetUserIDsByUsernames(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting mentioned users: %v", err)
+	}
+	mentioned := make([]int, 0, len(ids))
+	for _, name := range names {
+		if id, ok := ids[name]; ok {
+			mentioned = append(mentioned, id)
+		}
+	}
+	return mentioned, nil
+}
+
+// AnnouncePost tells users about a post that became visible: the author
+// of the discussion and of the comment replied to about a comment, the
+// mentioned users about any post, and the followers of the discussion,
+// its tags or the author. Every user hears about the post once, a reply
+// counts before a mention and a mention before activity. A comment also
+// goes to the clients streaming its discussion.
+func (s *ForumService) AnnouncePost(ctx context.Context, postType, postID string) error {
+	var notifications []models.Notification
+	var authorID int
+	var text string
+	recipients := map[int]bool{}
+	add := func(userID int, notificationType string, n models.Notification) {
+		if recipients[userID] {
+			return
+		}
+		recipients[userID] = true
+		n.UserID, n.Type, n.ActorID = userID, notificationType, &authorID
+		notifications = append(notifications, n)
+	}
+
+	var post models.Notification
+	var followed []models.SubscriptionTarget
+	if postType == models.PostComment {
+		comment, err := s.repo.GetComment(ctx, postID)
+		if err != nil {
+			return lookupError(err, "comment")
+		}
+		authorID, text = comment.AuthorID, comment.Content
+		s.publishComment(ctx, pubsub.CommentCreated, comment.ID)
+		post = models.Notification{DiscussionID: comment.DiscussionID, CommentID: comment.ID}
+		disc, err := s.repo.GetDiscussion(ctx, comment.DiscussionID)
+		if err != nil {
+			return lookupError(err, "discussion")
+		}
+		recipients[authorID] = true
+		followed = append(followed, models.SubscriptionTarget{Type: models.PostDiscussion, ID: disc.ID})
+		add(disc.AuthorID, models.NotifyReply, post)
+		if comment.RelatedTo != "" {
+			if related, err := s.repo.GetComment(ctx, comment.RelatedTo); err == nil {
+				add(related.AuthorID, models.NotifyReply, post)
+			}
+		}
+	} else {
+		disc, err := s.repo.GetDiscussion(ctx, postID)
+		if err != nil {
+			return lookupError(err, "discussion")
+		}
+		authorID, text = disc.AuthorID, disc.Title+"\n"+disc.Content
+		post = models.Notification{DiscussionID: disc.ID}
+		recipients[authorID] = true
+		for _, tag := range disc.Tags {
+			followed = append(followed, models.SubscriptionTarget{Type: models.TargetTag, ID: tag})
+		}
+	}
+	followed = append(followed, models.SubscriptionTarget{Type: models.TargetUser, ID: strconv.Itoa(authorID)})
+
+	mentioned, err := s.mentioned(ctx, text)
+	if err != nil {
+		return err
+	}
+	for _, userID := range mentioned {
+		add(userID, models.NotifyMention, post)
+	}
+	followers, err := s.followers(ctx, followed)
+	if err != nil {
+		return err
+	}
+	for userID, target := range followers {
+		activity := post
+		activity.Detail = target.Type
+		add(userID, models.NotifyActivity, activity)
+	}
+	s.notify(ctx, notifications...)
+	return nil
+}
+
+// notifyVote tells the author about a new vote, or a vote changed to
+// another type. Withdrawn votes are not worth a notification.
+func (s *ForumService) notifyVote(ctx context.Context, authorID, voterID int, prev, voteType string, post models.Notification) {
+	if voteType == voteNone || voteType == prev {
+		return
+	}
+	post.UserID, post.Type, post.ActorID, post.Detail = authorID, models.NotifyVote, &voterID, voteType
+	s.notify(ctx, post)
+}
@@ -0,0 +1,71 @@
+package forum
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/storage/mongo"
+)
+
+func (s *ForumService) GetPublicProfile(ctx context.Context, userID int) (*models.PublicProfile, error) {
+	user, err := s.users.GetUserById(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during getting user by id: %v", err)
+	}
+	discussions, comments, err := s.repo.CountAuthorContent(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error during counting content of user: %v", err)
+	}
+	return &models.PublicProfile{
+		ID:               user.ID,
+		Username:         user.Username,
+		Role:             user.Role,
+		Reputation:       user.Reputation,
+		JoinedAt:         user.CreatedAt,
+		DiscussionsCount: discussions,
+		CommentsCount:    comments,
+	}, nil
+}
+
+func pageSize(limit int) int {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
+
+func (s *ForumService) GetUserDiscussions(ctx context.Context, userID, limit int, cursor string) ([]models.DiscussionTopic, string, error) {
+	discussions, nextCursor, err := s.repo.GetDiscussionsByAuthor(ctx, userID, pageSize(limit), cursor)
+	if errors.Is(err, mongo.ErrInvalidCursor) {
+		return nil, "", ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error during getting discussions of user: %v", err)
+	}
+	if err = s.attachTopicAuthors(ctx, discussions); err != nil {
+		return nil, "", err
+	}
+	return discussions, nextCursor, nil
+}
+
+func (s *ForumService) GetUserComments(ctx context.Context, userID, limit int, cursor string) ([]models.Comment, string, error) {
+	comments, nextCursor, err := s.repo.GetCommentsByAuthor(ctx, userID, pageSize(limit), cursor)
+	if errors.Is(err, mongo.ErrInvalidCursor) {
+		return nil, "", ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error during getting comments of user: %v", err)
+	}
+	if err = s.attachCommentAuthors(ctx, comments); err != nil {
+		return nil, "", err
+	}
+	return comments, nextCursor, nil
+}
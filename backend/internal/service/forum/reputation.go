@@ -0,0 +1,76 @@
+package forum
+
+import (
+	"context"
+	"fmt"
+	"gohelp/internal/models"
+	"slices"
+)
+
+const (
+	voteLike    = "like"
+	voteDislike = "dislike"
+	voteNone    = "none"
+)
+
+func previousVote(likes, dislikes []int, userID int) string {
+	if slices.Contains(likes, userID) {
+		return voteLike
+	}
+	if slices.Contains(dislikes, userID) {
+		return voteDislike
+	}
+	return voteNone
+}
+
+func voteWeight(voteType string) int {
+	switch voteType {
+	case voteLike:
+		return models.ReputationLike
+	case voteDislike:
+		return models.ReputationDislike
+	}
+	return 0
+}
+
+// rewardVote moves reputation of the author when voter changes their vote
+// from prev to next.
+func (s *ForumService) rewardVote(ctx context.Context, authorID, voterID int, prev, next string) error {
+	if authorID == voterID {
+		return nil
+	}
+	delta := voteWeight(next) - voteWeight(prev)
+	if delta == 0 {
+		return nil
+	}
+	if err := s.users.AddReputation(ctx, map[int]int{authorID: delta}); err != nil {
+		return fmt.Errorf("error during updating reputation: %v", err)
+	}
+	return nil
+}
+
+// revokeReputation takes back reputation that content is about to stop
+// giving, e.g. because it is deleted.
+func (s *ForumService) revokeReputation(ctx context.Context, scores map[int]int) error {
+	deltas := make(map[int]int, len(scores))
+	for userID, score := range scores {
+		deltas[userID] = -score
+	}
+	if err := s.users.AddReputation(ctx, deltas); err != nil {
+		return fmt.Errorf("error during updating reputation: %v", err)
+	}
+	return nil
+}
+
+// RecomputeReputation rebuilds reputation of every user from the votes and
+// accepted answers stored with the content.
+func (s *ForumService) RecomputeReputation(ctx context.Context) error {
+	scores, err := s.repo.ReputationOfAll(ctx)
+	if err != nil {
+		return fmt.Errorf("error during counting reputation: %v", err)
+	}
+	if err = s.users.ResetReputation(ctx, scores); err != nil {
+		return fmt.Errorf("error during saving reputation: %v", err)
+	}
+	return nil
+}
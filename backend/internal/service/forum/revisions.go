@@ -0,0 +1,133 @@
+package forum
+
+import (
+	"context"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/service/rbac"
+	"gohelp/util"
+)
+
+// postState is what revisions need to know about a discussion or comment.
+type postState struct {
+	title        string
+	content      string
+	discussionID string
+	authorID     int
+	revisions    int
+}
+
+func (s *ForumService) getPost(ctx context.Context, postType, postID string) (*postState, error) {
+	switch postType {
+	case models.PostDiscussion:
+		disc, err := s.repo.GetDiscussion(ctx, postID)
+		if err != nil {
+			return nil, lookupError(err, "discussion")
+		}
+		return &postState{title: disc.Title, content: disc.Content, authorID: disc.AuthorID, revisions: disc.Revisions}, nil
+	case models.PostComment:
+		comm, err := s.repo.GetComment(ctx, postID)
+		if err != nil {
+			return nil, lookupError(err, "comment")
+		}
+		return &postState{content: comm.Content, discussionID: comm.DiscussionID, authorID: comm.AuthorID, revisions: comm.Revisions}, nil
+	}
+	return nil, apperr.Validation("unknown post type %q", postType)
+}
+
+// canReview tells if the user may look at the edit history of the post.
+func canReview(post *postState, userID int, userRole string) bool {
+	return post.authorID == userID || rbac.Can(userRole, rbac.RevisionViewAny)
+}
+
+// versionContent returns the content of the post in the given version, the
+// version after the last revision is the current content.
+func (s *ForumService) versionContent(ctx context.Context, postType, postID string, post *postState, version int) (string, error) {
+	if version < 1 || version > post.revisions+1 {
+		return "", apperr.NotFound("version %d not found", version)
+	}
+	if version == post.revisions+1 {
+		return post.content, nil
+	}
+	revision, err := s.repo.GetRevision(ctx, postType, postID, version)
+	if err != nil {
+		return "", lookupError(err, fmt.Sprintf("version %d", version))
+	}
+	return revision.Content, nil
+}
+
+// GetRevisions returns the saved revisions of the post and the number of
+// its current version.
+func (s *ForumService) GetRevisions(ctx context.Context, postType, postID string, userID int, userRole string) ([]models.Revision, int, error) {
+	post, err := s.getPost(ctx, postType, postID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !canReview(post, userID, userRole) {
+		return nil, 0, errNoPermissions
+	}
+	revisions, err := s.repo.GetRevisions(ctx, postType, postID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error during getting revisions: %v", err)
+	}
+	return revisions, post.revisions + 1, nil
+}
+
+// DiffRevisions compares two versions of the post.
+func (s *ForumService) DiffRevisions(ctx context.Context, postType, postID string, from, to, userID int, userRole string) ([]util.DiffChunk, error) {
+	post, err := s.getPost(ctx, postType, postID)
+	if err != nil {
+		return nil, err
+	}
+	if !canReview(post, userID, userRole) {
+		return nil, errNoPermissions
+	}
+	if to == 0 {
+		to = post.revisions + 1
+	}
+	fromContent, err := s.versionContent(ctx, postType, postID, post, from)
+	if err != nil {
+		return nil, err
+	}
+	toContent, err := s.versionContent(ctx, postType, postID, post, to)
+	if err != nil {
+		return nil, err
+	}
+	return util.Diff(fromContent, toContent), nil
+}
+
+// RollbackPost brings back the content of an earlier version and returns
+// the status of the post. The rollback is an edit by editorID itself, so
+// the replaced content stays in history, and it is screened like any
+// edit of editorID. Editors who review held posts are not screened, so
+// rollbacks by moderators are never held.
+func (s *ForumService) RollbackPost(ctx context.Context, postType, postID string, version, editorID int) (string, error) {
+	post, err := s.getPost(ctx, postType, postID)
+	if err != nil {
+		return "", err
+	}
+	content, err := s.versionContent(ctx, postType, postID, post, version)
+	if err != nil {
+		return "", err
+	}
+	if content == post.content {
+		return models.PostPublished, nil
+	}
+	verdict, err := s.screen(ctx, postType, post.title, content, editorID)
+	if err != nil {
+		return "", err
+	}
+	if postType == models.PostDiscussion {
+		err = s.repo.UpdateDiscussion(ctx, postID, content, editorID, verdict)
+	} else {
+		err = s.repo.UpdateComment(ctx, postID, content, editorID, verdict)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error during rolling back %s: %v", postType, err)
+	}
+	if postType == models.PostComment {
+		s.editedComment(ctx, postID, post.discussionID, verdict)
+	}
+	return postStatus(verdict), nil
+}
@@ -0,0 +1,153 @@
+package forum
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/storage/mongo"
+	"log"
+	"strconv"
+)
+
+type SubscriptionRepo interface {
+	Subscribe(ctx context.Context, userID int, target models.SubscriptionTarget) error
+	Unsubscribe(ctx context.Context, userID int, target models.SubscriptionTarget) error
+	GetSubscriptions(ctx context.Context, userID int) ([]models.Subscription, error)
+	GetSubscribers(ctx context.Context, targets []models.SubscriptionTarget) (map[int]models.SubscriptionTarget, error)
+	MoveSubscriptions(ctx context.Context, from, to models.SubscriptionTarget) error
+}
+
+// followTarget checks that the target exists and that the user may follow
+// it. User ids are returned in their canonical form.
+func (s *ForumService) followTarget(ctx context.Context, userID int, targetType, targetID string) (models.SubscriptionTarget, error) {
+	target := models.SubscriptionTarget{Type: targetType, ID: targetID}
+	switch targetType {
+	case models.PostDiscussion:
+		if _, err := s.repo.GetDiscussion(ctx, targetID); err != nil {
+			return target, lookupError(err, "discussion")
+		}
+	case models.TargetTag:
+		if _, err := s.repo.GetTag(ctx, targetID); err != nil {
+			return target, lookupError(err, fmt.Sprintf("tag %q", targetID))
+		}
+	case models.TargetUser:
+		followedID, err := strconv.Atoi(targetID)
+		if err != nil {
+			return target, ErrUserNotFound
+		}
+		if followedID == userID {
+			return target, apperr.Validation("you cannot follow yourself")
+		}
+		_, err = s.users.GetUserById(ctx, followedID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return target, ErrUserNotFound
+		}
+		if err != nil {
+			return target, fmt.Errorf("error during getting user by id: %v", err)
+		}
+		target.ID = strconv.Itoa(followedID)
+	default:
+		return target, apperr.Validation("cannot follow %q, choose a discussion, a tag or a user", targetType)
+	}
+	return target, nil
+}
+
+func (s *ForumService) Follow(ctx context.Context, userID int, targetType, targetID string) error {
+	target, err := s.followTarget(ctx, userID, targetType, targetID)
+	if err != nil {
+		return err
+	}
+	if err = s.subscriptions.Subscribe(ctx, userID, target); err != nil {
+		return fmt.Errorf("error during subscribing: %v", err)
+	}
+	return nil
+}
+
+// Unfollow works for targets that are gone too.
+func (s *ForumService) Unfollow(ctx context.Context, userID int, targetType, targetID string) error {
+	if targetType == models.TargetUser {
+		if followedID, err := strconv.Atoi(targetID); err == nil {
+			targetID = strconv.Itoa(followedID)
+		}
+	}
+	err := s.subscriptions.Unsubscribe(ctx, userID, models.SubscriptionTarget{Type: targetType, ID: targetID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperr.NotFound("subscription not found")
+	}
+	if err != nil {
+		return fmt.Errorf("error during unsubscribing: %v", err)
+	}
+	return nil
+}
+
+func (s *ForumService) GetSubscriptions(ctx context.Context, userID int) ([]models.Subscription, error) {
+	subscriptions, err := s.subscriptions.GetSubscriptions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting subscriptions: %v", err)
+	}
+	return subscriptions, nil
+}
+
+// subscribeAuthor makes the author follow the new discussion. The
+// discussion is stored already, so failing is only logged.
+func (s *ForumService) subscribeAuthor(ctx context.Context, authorID int, discussionID string) {
+	target := models.SubscriptionTarget{Type: models.PostDiscussion, ID: discussionID}
+	if err := s.subscriptions.Subscribe(ctx, authorID, target); err != nil {
+		log.Printf("failed to subscribe author %d to discussion %s: %v", authorID, discussionID, err)
+	}
+}
+
+// followers returns who follows any of the targets with what they follow,
+// the earlier targets count first.
+func (s *ForumService) followers(ctx context.Context, targets []models.SubscriptionTarget) (map[int]models.SubscriptionTarget, error) {
+	subscribers, err := s.subscriptions.GetSubscribers(ctx, targets)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting subscribers: %v", err)
+	}
+	return subscribers, nil
+}
+
+// GetFeed returns one page of the discussions and comments posted in what
+// the user follows, the latest first. Comments show up from followed
+// discussions and users, not from followed tags.
+func (s *ForumService) GetFeed(ctx context.Context, userID, limit int, cursor string) ([]models.FeedItem, string, error) {
+	subscriptions, err := s.subscriptions.GetSubscriptions(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("error during getting subscriptions: %v", err)
+	}
+	query := models.FeedQuery{UserID: userID, Limit: pageSize(limit), Cursor: cursor}
+	for _, sub := range subscriptions {
+		switch sub.TargetType {
+		case models.PostDiscussion:
+			query.Discussions = append(query.Discussions, sub.TargetID)
+		case models.TargetTag:
+			query.Tags = append(query.Tags, sub.TargetID)
+		case models.TargetUser:
+			if followedID, err := strconv.Atoi(sub.TargetID); err == nil {
+				query.Users = append(query.Users, followedID)
+			}
+		}
+	}
+	items, nextCursor, err := s.repo.GetFeed(ctx, query)
+	if errors.Is(err, mongo.ErrInvalidCursor) {
+		return nil, "", ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error during getting feed: %v", err)
+	}
+
+	authors := authorSet{}
+	for _, item := range items {
+		authors.add(item.AuthorID)
+	}
+	if err = s.loadAuthors(ctx, authors); err != nil {
+		return nil, "", err
+	}
+	for i := range items {
+		items[i].Author = authors[items[i].AuthorID]
+	}
+	return items, nextCursor, nil
+}
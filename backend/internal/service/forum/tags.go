@@ -0,0 +1,157 @@
+package forum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/service/rbac"
+	"gohelp/internal/storage/mongo"
+	"gohelp/util"
+)
+
+// checkTags normalizes the tags and makes sure that they are well formed,
+// that there are not too many of them and that every one of them is in
+// the catalog. Repeated tags are dropped before they are counted, so the
+// catalog count can't be off, and the tags to store are returned.
+func (s *ForumService) checkTags(ctx context.Context, tags []string) ([]string, error) {
+	tags = util.NormalizeTags(tags)
+	if len(tags) > models.MaxTagsPerDiscussion {
+		return nil, apperr.Validation("discussion can have at most %d tags", models.MaxTagsPerDiscussion)
+	}
+	if len(tags) == 0 {
+		return tags, nil
+	}
+	for _, tag := range tags {
+		if err := util.ValidateTag(tag); err != nil {
+			return nil, apperr.Validation("%v", err)
+		}
+	}
+	count, err := s.repo.CountExistingTags(ctx, tags)
+	if err != nil {
+		return nil, fmt.Errorf("error during checking tags: %v", err)
+	}
+	if count != int64(len(tags)) {
+		return nil, apperr.Validation("unknown tag, choose tags from the catalog")
+	}
+	return tags, nil
+}
+
+func (s *ForumService) GetTags(ctx context.Context) ([]models.Tag, error) {
+	tags, err := s.repo.GetTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting tags: %v", err)
+	}
+	return tags, nil
+}
+
+func (s *ForumService) CreateTag(ctx context.Context, name, description string) error {
+	if err := util.ValidateTag(name); err != nil {
+		return apperr.Validation("%v", err)
+	}
+	err := s.repo.CreateTag(ctx, models.Tag{Name: name, Description: description})
+	if errors.Is(err, mongo.ErrTagExists) {
+		return apperr.Conflict("tag %q already exists", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error during creating tag: %v", err)
+	}
+	return nil
+}
+
+func (s *ForumService) DescribeTag(ctx context.Context, name, description string) error {
+	err := s.repo.DescribeTag(ctx, name, description)
+	if errors.Is(err, mongo.ErrNotFound) {
+		return apperr.NotFound("tag %q not found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error during describing tag: %v", err)
+	}
+	return nil
+}
+
+func (s *ForumService) moveTagFollowers(ctx context.Context, from, to string) error {
+	err := s.subscriptions.MoveSubscriptions(ctx,
+		models.SubscriptionTarget{Type: models.TargetTag, ID: from},
+		models.SubscriptionTarget{Type: models.TargetTag, ID: to})
+	if err != nil {
+		return fmt.Errorf("error during moving tag subscriptions: %v", err)
+	}
+	return nil
+}
+
+// RenameTag moves the catalog entry to the new name and retags every
+// discussion that used the old one. Followers of the tag keep following
+// it.
+func (s *ForumService) RenameTag(ctx context.Context, name, newName string) error {
+	if err := util.ValidateTag(newName); err != nil {
+		return apperr.Validation("%v", err)
+	}
+	tag, err := s.repo.GetTag(ctx, name)
+	if err != nil {
+		return lookupError(err, fmt.Sprintf("tag %q", name))
+	}
+	err = s.repo.CreateTag(ctx, models.Tag{Name: newName, Description: tag.Description})
+	if errors.Is(err, mongo.ErrTagExists) {
+		return apperr.Conflict("tag %q already exists", newName)
+	}
+	if err != nil {
+		return fmt.Errorf("error during creating tag: %v", err)
+	}
+	if err = s.repo.ReplaceTag(ctx, name, newName); err != nil {
+		return fmt.Errorf("error during retagging discussions: %v", err)
+	}
+	if err = s.moveTagFollowers(ctx, name, newName); err != nil {
+		return err
+	}
+	if err = s.repo.DeleteTag(ctx, name); err != nil {
+		return fmt.Errorf("error during deleting tag: %v", err)
+	}
+	return nil
+}
+
+// MergeTag retags every discussion using source with target and removes
+// source from the catalog. Followers of source follow target instead.
+func (s *ForumService) MergeTag(ctx context.Context, source, target string) error {
+	if source == target {
+		return apperr.Validation("tag cannot be merged into itself")
+	}
+	if _, err := s.repo.GetTag(ctx, source); err != nil {
+		return lookupError(err, fmt.Sprintf("tag %q", source))
+	}
+	if _, err := s.repo.GetTag(ctx, target); err != nil {
+		return lookupError(err, fmt.Sprintf("tag %q", target))
+	}
+	if err := s.repo.ReplaceTag(ctx, source, target); err != nil {
+		return fmt.Errorf("error during retagging discussions: %v", err)
+	}
+	if err := s.moveTagFollowers(ctx, source, target); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTag(ctx, source); err != nil {
+		return fmt.Errorf("error during deleting tag: %v", err)
+	}
+	return nil
+}
+
+func (s *ForumService) UpdateDiscussionTags(ctx context.Context, discussionID string, tags []string, authorID int, userRole string) (*models.Discussion, error) {
+	disc, err := s.repo.GetDiscussion(ctx, discussionID)
+	if err != nil {
+		return nil, lookupError(err, "discussion")
+	}
+	if disc.AuthorID != authorID && !rbac.Can(userRole, rbac.DiscussionRetagAny) {
+		return nil, errNoPermissions
+	}
+	if tags, err = s.checkTags(ctx, tags); err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	if err = s.repo.SetDiscussionTags(ctx, discussionID, tags); err != nil {
+		return nil, fmt.Errorf("error during updating tags: %v", err)
+	}
+	disc.Tags = tags
+	return disc, nil
+}
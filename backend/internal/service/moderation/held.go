@@ -0,0 +1,100 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/storage/mongo"
+)
+
+const defaultHeldLimit = 20
+
+// ListHeld returns one page of the posts waiting for review, the oldest
+// first, with their authors.
+func (s *ModerationService) ListHeld(ctx context.Context, query models.HeldListQuery) ([]models.HeldPost, string, error) {
+	if query.Limit == 0 {
+		query.Limit = defaultHeldLimit
+	}
+	posts, next, err := s.forum.GetHeldPosts(ctx, query)
+	if errors.Is(err, mongo.ErrInvalidCursor) {
+		return nil, "", apperr.Validation("invalid cursor")
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error during getting held posts: %v", err)
+	}
+	if len(posts) == 0 {
+		return posts, next, nil
+	}
+	ids := make([]int, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.AuthorID)
+	}
+	authors, err := s.users.GetAuthors(ctx, ids)
+	if err != nil {
+		return nil, "", fmt.Errorf("error during getting authors: %v", err)
+	}
+	byID := make(map[int]*models.Author, len(authors))
+	for i := range authors {
+		byID[authors[i].ID] = &authors[i]
+	}
+	for i := range posts {
+		posts[i].Author = byID[posts[i].AuthorID]
+	}
+	return posts, next, nil
+}
+
+func (s *ModerationService) heldPost(ctx context.Context, postType, postID string) (*models.HeldPost, error) {
+	if postType != models.PostDiscussion && postType != models.PostComment {
+		return nil, apperr.Validation("unknown post type %q", postType)
+	}
+	post, err := s.forum.GetHeldPost(ctx, postType, postID)
+	if errors.Is(err, mongo.ErrNotFound) {
+		return nil, apperr.NotFound("%s is not held for review", postType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during getting held post: %v", err)
+	}
+	return post, nil
+}
+
+// reviewError tells that another moderator decided on the post first.
+func reviewError(err error, postType string) error {
+	if errors.Is(err, mongo.ErrNotFound) {
+		return apperr.Conflict("%s is already reviewed", postType)
+	}
+	return fmt.Errorf("error during reviewing held post: %v", err)
+}
+
+// ApproveHeld publishes the held post. A comment can't be published once
+// its discussion is gone.
+func (s *ModerationService) ApproveHeld(ctx context.Context, postType, postID string, moderatorID int, note string) error {
+	post, err := s.heldPost(ctx, postType, postID)
+	if err != nil {
+		return err
+	}
+	if post.Type == models.PostComment {
+		if _, err := s.forum.GetDiscussion(ctx, post.DiscussionID); errors.Is(err, mongo.ErrNotFound) {
+			return apperr.Conflict("discussion of the comment is deleted, reject the comment instead")
+		} else if err != nil {
+			return fmt.Errorf("error during getting discussion: %v", err)
+		}
+	}
+	if err = s.forum.ApproveHeld(ctx, *post, moderatorID, note); err != nil {
+		return reviewError(err, postType)
+	}
+	return nil
+}
+
+// RejectHeld keeps the held post hidden for good.
+func (s *ModerationService) RejectHeld(ctx context.Context, postType, postID string, moderatorID int, note string) error {
+	post, err := s.heldPost(ctx, postType, postID)
+	if err != nil {
+		return err
+	}
+	if err = s.forum.RejectHeld(ctx, *post, moderatorID, note); err != nil {
+		return reviewError(err, postType)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Package moderation handles reports users file about content and other
+// users, the posts the screening held, and the queues moderators work
+// through.
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/service/auth"
+	forumsvc "gohelp/internal/service/forum"
+	"gohelp/internal/storage/mongo"
+	"gohelp/internal/storage/postgresql"
+	"log"
+	"strconv"
+	"time"
+)
+
+const defaultReportsLimit = 20
+
+// claimTimeout is how long a claim on a report lasts without being
+// resolved or released, in case the server stopped in the middle.
+const claimTimeout = 10 * time.Minute
+
+type ReportRepo interface {
+	AddFlag(ctx context.Context, report models.Report, flag models.ReportFlag) (*models.Report, error)
+	GetReport(ctx context.Context, reportID int64) (*models.Report, error)
+	GetReports(ctx context.Context, query models.ReportListQuery) ([]models.Report, string, error)
+	ClaimReport(ctx context.Context, reportID int64, staleAfter time.Duration) error
+	ReleaseReport(ctx context.Context, reportID int64) error
+	ResolveReport(ctx context.Context, resolution models.ReportResolution) (*models.ReportResolution, error)
+}
+
+type ForumRepo interface {
+	GetDiscussion(ctx context.Context, discussionID string) (*models.Discussion, error)
+	GetComment(ctx context.Context, commentID string) (*models.Comment, error)
+	GetHeldPosts(ctx context.Context, query models.HeldListQuery) ([]models.HeldPost, string, error)
+	GetHeldPost(ctx context.Context, postType, postID string) (*models.HeldPost, error)
+	ApproveHeld(ctx context.Context, post models.HeldPost, moderatorID int, note string) error
+	RejectHeld(ctx context.Context, post models.HeldPost, moderatorID int, note string) error
+}
+
+type UserRepo interface {
+	GetUserById(ctx context.Context, userID int) (*models.User, error)
+	GetAuthors(ctx context.Context, ids []int) ([]models.Author, error)
+}
+
+type ModerationService struct {
+	reports  ReportRepo
+	forum    ForumRepo
+	users    UserRepo
+	accounts Accounts
+	content  Content
+}
+
+func NewModerationService(reports *postgresql.ReportRepository, forum *mongo.ForumStorage, users *postgresql.UserRepository,
+	accounts *auth.UserService, content *forumsvc.ForumService) *ModerationService {
+	return &ModerationService{reports: reports, forum: forum, users: users, accounts: accounts, content: content}
+}
+
+// targetAuthor returns who is responsible for the target: the author of
+// the content or the user itself.
+func (s *ModerationService) targetAuthor(ctx context.Context, targetType, targetID string) (int, error) {
+	switch targetType {
+	case models.PostDiscussion:
+		disc, err := s.forum.GetDiscussion(ctx, targetID)
+		if errors.Is(err, mongo.ErrNotFound) {
+			return 0, apperr.NotFound("discussion not found")
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error during getting discussion: %v", err)
+		}
+		return disc.AuthorID, nil
+	case models.PostComment:
+		comm, err := s.forum.GetComment(ctx, targetID)
+		if errors.Is(err, mongo.ErrNotFound) {
+			return 0, apperr.NotFound("comment not found")
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error during getting comment: %v", err)
+		}
+		return comm.AuthorID, nil
+	case models.TargetUser:
+		userID, err := strconv.Atoi(targetID)
+		if err != nil {
+			return 0, apperr.Validation("invalid user id")
+		}
+		if _, err := s.users.GetUserById(ctx, userID); errors.Is(err, sql.ErrNoRows) {
+			return 0, apperr.NotFound("user not found")
+		} else if err != nil {
+			return 0, fmt.Errorf("error during getting user by id: %v", err)
+		}
+		return userID, nil
+	}
+	return 0, apperr.Validation("unknown target type %q", targetType)
+}
+
+// FileReport flags the target for the reporter. Flags of different users
+// on the same target are gathered in one report, a user can flag it once.
+func (s *ModerationService) FileReport(ctx context.Context, reporterID int, request models.CreateReportRequest) (*models.Report, error) {
+	authorID, err := s.targetAuthor(ctx, request.TargetType, request.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if authorID == reporterID {
+		return nil, apperr.Validation("you can't report yourself")
+	}
+	report, err := s.reports.AddFlag(ctx,
+		models.Report{TargetType: request.TargetType, TargetID: request.TargetID, AuthorID: authorID},
+		models.ReportFlag{ReporterID: reporterID, Category: request.Category, Text: request.Text})
+	if errors.Is(err, postgresql.ErrAlreadyReported) {
+		return nil, apperr.Conflict("you have already reported this")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during saving report: %v", err)
+	}
+	return report, nil
+}
+
+// ListReports returns one page of open reports, the moderation queue, or
+// of the ones being resolved or resolved.
+func (s *ModerationService) ListReports(ctx context.Context, query models.ReportListQuery) ([]models.Report, string, error) {
+	if query.Status == "" {
+		query.Status = models.ReportOpen
+	}
+	if query.Status != models.ReportOpen && query.Status != models.ReportResolving && query.Status != models.ReportResolved {
+		return nil, "", apperr.Validation("unknown status %q", query.Status)
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultReportsLimit
+	}
+	reports, next, err := s.reports.GetReports(ctx, query)
+	if errors.Is(err, postgresql.ErrInvalidCursor) {
+		return nil, "", apperr.Validation("invalid cursor")
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error during getting reports: %v", err)
+	}
+	return reports, next, nil
+}
+
+// GetReport returns the report with every flag and resolution.
+func (s *ModerationService) GetReport(ctx context.Context, reportID int64) (*models.Report, error) {
+	report, err := s.reports.GetReport(ctx, reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.NotFound("report not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during getting report: %v", err)
+	}
+	return report, nil
+}
+
+// PrepareResolution checks that the action fits the report and claims the
+// report, before the action is carried out. The claim ends with
+// RecordResolution, or with ReleaseResolution if the action fails.
+func (s *ModerationService) PrepareResolution(ctx context.Context, reportID int64, request models.ResolveReportRequest) (*models.Report, error) {
+	report, err := s.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != models.ReportOpen {
+		return nil, apperr.Conflict("report is already resolved")
+	}
+	switch request.Action {
+	case models.ResolveDelete:
+		if report.TargetType == models.TargetUser {
+			return nil, apperr.Validation("users can't be deleted, suspend them instead")
+		}
+	case models.ResolveSuspend:
+		if request.Suspension == nil {
+			return nil, apperr.Validation("suspension is required to suspend the author")
+		}
+	}
+	err = s.reports.ClaimReport(ctx, reportID, claimTimeout)
+	if errors.Is(err, postgresql.ErrReportClosed) {
+		return nil, apperr.Conflict("report is already resolved or being resolved")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during claiming report: %v", err)
+	}
+	return report, nil
+}
+
+// ReleaseResolution opens the claimed report again after the action
+// failed. Failing it is only logged, the claim runs out on its own.
+func (s *ModerationService) ReleaseResolution(ctx context.Context, reportID int64) {
+	if err := s.reports.ReleaseReport(ctx, reportID); err != nil {
+		log.Printf("failed to release report %d: %v", reportID, err)
+	}
+}
+
+// RecordResolution closes the report and adds what was done to the audit
+// log.
+func (s *ModerationService) RecordResolution(ctx context.Context, resolution models.ReportResolution) (*models.ReportResolution, error) {
+	created, err := s.reports.ResolveReport(ctx, resolution)
+	if errors.Is(err, postgresql.ErrReportClosed) {
+		return nil, apperr.Conflict("report is already resolved")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error during resolving report: %v", err)
+	}
+	return created, nil
+}
@@ -0,0 +1,81 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/util"
+)
+
+// Accounts keeps the sanctions and the sessions of users.
+type Accounts interface {
+	Suspend(ctx context.Context, actorID int, actorRole string, userID int, request models.SanctionRequest) (*models.Sanction, error)
+	LiftSanctions(ctx context.Context, actorID int, actorRole string, userID int, reason string) error
+	LogoutAll(ctx context.Context, userID int) error
+	RestorableSanctions(ctx context.Context, userID int) ([]int64, error)
+	ContentRestored(ctx context.Context, sanctionIDs []int64) error
+}
+
+// Content hides and brings back what sanctioned users wrote.
+type Content interface {
+	WipeUserContent(ctx context.Context, userID int, sanctionID int64) error
+	RestoreUserContent(ctx context.Context, userID int, sanctionIDs []int64) error
+}
+
+// SanctionUser suspends the user, ends their sessions and wipes their
+// content if asked to. Once the sanction is written it stands, the steps
+// after it are retried and if they still fail the sanction is returned with
+// the error.
+func (s *ModerationService) SanctionUser(ctx context.Context, actorID int, actorRole string, userID int, request models.SanctionRequest) (*models.Sanction, error) {
+	sanction, err := s.accounts.Suspend(ctx, actorID, actorRole, userID, request)
+	if err != nil {
+		return nil, err
+	}
+	err = util.Retry(ctx, func() error {
+		return s.accounts.LogoutAll(ctx, userID)
+	})
+	if err != nil {
+		return sanction, fmt.Errorf("user %d is suspended, but their sessions were not ended: %v", userID, err)
+	}
+	if request.WipeContent {
+		if err = s.content.WipeUserContent(ctx, userID, sanction.ID); err != nil {
+			return sanction, fmt.Errorf("user %d is suspended, but their content was not wiped: %v", userID, err)
+		}
+	}
+	return sanction, nil
+}
+
+// LiftUserSanctions lifts the sanctions of the user and restores their
+// content if asked to.
+func (s *ModerationService) LiftUserSanctions(ctx context.Context, actorID int, actorRole string, userID int, request models.LiftSanctionRequest) error {
+	if err := s.accounts.LiftSanctions(ctx, actorID, actorRole, userID, request.Reason); err != nil {
+		return err
+	}
+	if !request.RestoreContent {
+		return nil
+	}
+	if _, err := s.RestoreUserContent(ctx, userID); err != nil {
+		return fmt.Errorf("sanctions of user %d are lifted, but their content was not restored: %v", userID, err)
+	}
+	return nil
+}
+
+// RestoreUserContent brings back what sanctions of the user that are not
+// active anymore hid and returns how many sanctions it undid. Recording
+// the restore is retried, the content is back already.
+func (s *ModerationService) RestoreUserContent(ctx context.Context, userID int) (int, error) {
+	ids, err := s.accounts.RestorableSanctions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err = s.content.RestoreUserContent(ctx, userID, ids); err != nil {
+		return 0, err
+	}
+	err = util.Retry(ctx, func() error {
+		return s.accounts.ContentRestored(ctx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
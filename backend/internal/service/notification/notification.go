@@ -0,0 +1,141 @@
+// Package notification keeps what users should learn about: replies to
+// their posts, mentions, accepted answers and votes.
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gohelp/internal/models"
+	"gohelp/internal/service/apperr"
+	"gohelp/internal/storage/postgresql"
+	"gohelp/pkg/pubsub"
+)
+
+const defaultNotificationsLimit = 20
+
+type NotificationRepo interface {
+	CreateNotifications(ctx context.Context, notifications []models.Notification) ([]models.Notification, error)
+	GetNotifications(ctx context.Context, query models.NotificationListQuery) ([]models.Notification, string, error)
+	CountUnread(ctx context.Context, userID int) (int, error)
+	MarkRead(ctx context.Context, userID int, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID int) (int64, error)
+	GetPreferences(ctx context.Context, userID int) (models.NotificationPreferences, error)
+	SetPreferences(ctx context.Context, userID int, preferences models.NotificationPreferences) error
+}
+
+type Publisher interface {
+	Publish(topic string, event pubsub.Event)
+}
+
+type NotificationService struct {
+	repo   NotificationRepo
+	events Publisher
+}
+
+func NewNotificationService(repo *postgresql.NotificationRepository, events *pubsub.Hub) *NotificationService {
+	return &NotificationService{repo: repo, events: events}
+}
+
+// Notify stores the notifications and streams them to their receivers.
+// Users never hear about what they did themselves, and receivers who
+// switched the type off get nothing.
+func (s *NotificationService) Notify(ctx context.Context, notifications ...models.Notification) error {
+	wanted := make([]models.Notification, 0, len(notifications))
+	for _, n := range notifications {
+		if n.ActorID != nil && *n.ActorID == n.UserID {
+			continue
+		}
+		wanted = append(wanted, n)
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+	created, err := s.repo.CreateNotifications(ctx, wanted)
+	if err != nil {
+		return fmt.Errorf("error during saving notifications: %v", err)
+	}
+	for _, n := range created {
+		s.events.Publish(pubsub.UserTopic(n.UserID), pubsub.Event{Type: pubsub.Notification, Data: n})
+	}
+	return nil
+}
+
+// List returns one page of notifications of the user and how many of all
+// of them are unread.
+func (s *NotificationService) List(ctx context.Context, query models.NotificationListQuery) ([]models.Notification, int, string, error) {
+	if query.Limit == 0 {
+		query.Limit = defaultNotificationsLimit
+	}
+	notifications, next, err := s.repo.GetNotifications(ctx, query)
+	if errors.Is(err, postgresql.ErrInvalidCursor) {
+		return nil, 0, "", apperr.Validation("invalid cursor")
+	}
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("error during getting notifications: %v", err)
+	}
+	unread, err := s.repo.CountUnread(ctx, query.UserID)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("error during counting unread notifications: %v", err)
+	}
+	return notifications, unread, next, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID int, notificationID int64) error {
+	err := s.repo.MarkRead(ctx, userID, notificationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperr.NotFound("notification not found")
+	}
+	if err != nil {
+		return fmt.Errorf("error during marking notification read: %v", err)
+	}
+	return nil
+}
+
+// MarkAllRead marks every notification of the user as read and returns
+// how many were unread.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	marked, err := s.repo.MarkAllRead(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error during marking notifications read: %v", err)
+	}
+	return marked, nil
+}
+
+// Preferences returns every type with whether the user receives it.
+func (s *NotificationService) Preferences(ctx context.Context, userID int) (models.NotificationPreferences, error) {
+	set, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error during getting notification preferences: %v", err)
+	}
+	preferences := make(models.NotificationPreferences, len(models.NotificationTypes))
+	for _, t := range models.NotificationTypes {
+		enabled, ok := set[t]
+		preferences[t] = enabled || !ok
+	}
+	return preferences, nil
+}
+
+// SetPreferences switches the given types on or off, other types keep
+// their setting.
+func (s *NotificationService) SetPreferences(ctx context.Context, userID int, preferences models.NotificationPreferences) (models.NotificationPreferences, error) {
+	for t := range preferences {
+		if !validType(t) {
+			return nil, apperr.Validation("unknown notification type %q", t)
+		}
+	}
+	if err := s.repo.SetPreferences(ctx, userID, preferences); err != nil {
+		return nil, fmt.Errorf("error during saving notification preferences: %v", err)
+	}
+	return s.Preferences(ctx, userID)
+}
+
+func validType(notificationType string) bool {
+	for _, t := range models.NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
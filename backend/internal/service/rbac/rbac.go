@@ -0,0 +1,101 @@
+// Package rbac maps roles of users to the permissions they grant. Checks
+// ask for a permission and never compare role names, so what a role may do
+// is decided here only.
+package rbac
+
+import (
+	"gohelp/internal/models"
+	"sort"
+)
+
+// Permission is a named action that not every user may do. Permissions
+// ending with .any allow the action on content of other users, authors
+// can always do it on their own content.
+type Permission string
+
+const (
+	DiscussionDeleteAny Permission = "discussion.delete.any"
+	DiscussionRetagAny  Permission = "discussion.retag.any"
+	CommentDeleteAny    Permission = "comment.delete.any"
+	RevisionViewAny     Permission = "revision.view.any"
+	RevisionRollback    Permission = "revision.rollback"
+	TagManage           Permission = "tag.manage"
+	UserBan             Permission = "user.ban"
+	ReportReview        Permission = "report.review"
+	PostReview          Permission = "post.review"
+	ContentRestore      Permission = "content.restore"
+	RoleAssign          Permission = "role.assign"
+	ReputationRecompute Permission = "reputation.recompute"
+)
+
+// roles lists the roles from the least to the most powerful. Every role
+// has the permissions of the roles before it.
+var roles = []struct {
+	name        string
+	permissions []Permission
+}{
+	{models.UserRole, nil},
+	{models.TrustedRole, []Permission{DiscussionRetagAny}},
+	{models.ModeratorRole, []Permission{DiscussionDeleteAny, CommentDeleteAny, RevisionViewAny, RevisionRollback, TagManage, UserBan, ReportReview, PostReview}},
+	{models.AdministrationRole, []Permission{RoleAssign, ReputationRecompute, ContentRestore}},
+}
+
+var (
+	rank   = make(map[string]int, len(roles))
+	grants = make(map[string]map[Permission]bool, len(roles))
+)
+
+func init() {
+	inherited := map[Permission]bool{}
+	for i, role := range roles {
+		granted := make(map[Permission]bool, len(inherited)+len(role.permissions))
+		for p := range inherited {
+			granted[p] = true
+		}
+		for _, p := range role.permissions {
+			granted[p] = true
+		}
+		rank[role.name] = i
+		grants[role.name] = granted
+		inherited = granted
+	}
+}
+
+// Can tells if the role grants the permission. Unknown roles grant
+// nothing.
+func Can(role string, permission Permission) bool {
+	return grants[role][permission]
+}
+
+// Valid tells if the role exists.
+func Valid(role string) bool {
+	_, ok := rank[role]
+	return ok
+}
+
+// Outranks tells if role is more powerful than other. Users can act on
+// accounts of lower roles only.
+func Outranks(role, other string) bool {
+	r, ok := rank[role]
+	return ok && r > rank[other]
+}
+
+// Role is a role with its permissions, as shown to administrators.
+type Role struct {
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Roles returns every role from the least to the most powerful.
+func Roles() []Role {
+	list := make([]Role, 0, len(roles))
+	for _, role := range roles {
+		permissions := make([]Permission, 0, len(grants[role.name]))
+		for p := range grants[role.name] {
+			permissions = append(permissions, p)
+		}
+		sort.Slice(permissions, func(i, j int) bool { return permissions[i] < permissions[j] })
+		list = append(list, Role{Name: role.name, Permissions: permissions})
+	}
+	return list
+}
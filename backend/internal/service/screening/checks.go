@@ -0,0 +1,159 @@
+package screening
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BannedWords scores posts that contain one of the words, as a whole word
+// in any case, or match one of the patterns.
+type BannedWords struct {
+	Score    int
+	words    *regexp.Regexp
+	patterns []*regexp.Regexp
+}
+
+func NewBannedWords(score int, words, patterns []string) (*BannedWords, error) {
+	check := &BannedWords{Score: score}
+	if len(words) > 0 {
+		quoted := make([]string, len(words))
+		for i, word := range words {
+			quoted[i] = regexp.QuoteMeta(word)
+		}
+		check.words = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		check.patterns = append(check.patterns, re)
+	}
+	return check, nil
+}
+
+func (c *BannedWords) Check(ctx context.Context, post Post) (Result, error) {
+	text := post.Title + "\n" + post.Content
+	if c.words != nil {
+		if word := c.words.FindString(text); word != "" {
+			return Result{Score: c.Score, Reasons: []string{fmt.Sprintf("contains banned word %q", strings.ToLower(word))}}, nil
+		}
+	}
+	for _, re := range c.patterns {
+		if re.MatchString(text) {
+			return Result{Score: c.Score, Reasons: []string{fmt.Sprintf("matches banned pattern %q", re.String())}}, nil
+		}
+	}
+	return Result{}, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(https?://|www\.)\S+`)
+
+// LinkLimit scores posts with more than MaxLinks links from authors who
+// joined less than NewAccountAge ago or have less than MinReputation.
+type LinkLimit struct {
+	Score         int
+	MaxLinks      int
+	MinReputation int
+	NewAccountAge time.Duration
+}
+
+func (c *LinkLimit) Check(ctx context.Context, post Post) (Result, error) {
+	newcomer := time.Since(post.JoinedAt) < c.NewAccountAge || post.Reputation < c.MinReputation
+	if !newcomer {
+		return Result{}, nil
+	}
+	links := len(linkPattern.FindAllString(post.Title+"\n"+post.Content, -1))
+	if links <= c.MaxLinks {
+		return Result{}, nil
+	}
+	return Result{Score: c.Score, Reasons: []string{fmt.Sprintf("%d links from a new or low reputation account", links)}}, nil
+}
+
+// PostHistory gives the content the author posted since the time, deleted
+// and held posts included.
+type PostHistory interface {
+	RecentContent(ctx context.Context, authorID int, since time.Time) ([]string, error)
+}
+
+// Duplicates scores posts that repeat a post of the same author from the
+// last Window, ignoring case and whitespace.
+type Duplicates struct {
+	Score   int
+	Window  time.Duration
+	History PostHistory
+}
+
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+func (c *Duplicates) Check(ctx context.Context, post Post) (Result, error) {
+	recent, err := c.History.RecentContent(ctx, post.AuthorID, time.Now().Add(-c.Window))
+	if err != nil {
+		return Result{}, fmt.Errorf("error during getting recent posts: %v", err)
+	}
+	content := normalize(post.Content)
+	for _, earlier := range recent {
+		if normalize(earlier) == content {
+			return Result{Score: c.Score, Reasons: []string{"duplicate of an earlier post"}}, nil
+		}
+	}
+	return Result{}, nil
+}
+
+// Hook asks an external service to score the post. The post is sent as
+// json in a POST request and the service answers with
+// {"score": 0, "reasons": []}. A failing service lets the post through,
+// so an outage of the service does not stop the forum.
+type Hook struct {
+	URL    string
+	Client *http.Client
+}
+
+func (c *Hook) Check(ctx context.Context, post Post) (Result, error) {
+	result, err := c.call(ctx, post)
+	if err != nil {
+		log.Printf("screening hook failed: %v", err)
+		return Result{}, nil
+	}
+	return result, nil
+}
+
+func (c *Hook) call(ctx context.Context, post Post) (Result, error) {
+	body, err := json.Marshal(post)
+	if err != nil {
+		return Result{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var answer struct {
+		Score   int      `json:"score"`
+		Reasons []string `json:"reasons"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return Result{}, err
+	}
+	if answer.Score != 0 && len(answer.Reasons) == 0 {
+		answer.Reasons = []string{"scored by the screening hook"}
+	}
+	return Result{Score: answer.Score, Reasons: answer.Reasons}, nil
+}
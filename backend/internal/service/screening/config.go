@@ -0,0 +1,147 @@
+package screening
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Config is the file SCREENING_FILE points to, for example
+//
+//	{
+//	  "hold_score": 10,
+//	  "banned_words": {"words": ["casino"], "patterns": ["(?i)free\\s+money"], "score": 10},
+//	  "links": {"max_links": 2, "min_reputation": 10, "new_account_age": "72h", "score": 10},
+//	  "duplicates": {"window": "24h", "score": 10},
+//	  "hook": {"url": "http://localhost:9000/score", "timeout": "2s"}
+//	}
+//
+// Every field is optional. A section without a score scores hold_score, so
+// a single finding holds the post. Set links or duplicates to
+// {"disabled": true} to turn them off.
+type Config struct {
+	HoldScore   int `json:"hold_score"`
+	BannedWords struct {
+		Words    []string `json:"words"`
+		Patterns []string `json:"patterns"`
+		Score    int      `json:"score"`
+	} `json:"banned_words"`
+	Links struct {
+		Disabled      bool     `json:"disabled"`
+		MaxLinks      *int     `json:"max_links"`
+		MinReputation *int     `json:"min_reputation"`
+		NewAccountAge duration `json:"new_account_age"`
+		Score         int      `json:"score"`
+	} `json:"links"`
+	Duplicates struct {
+		Disabled bool     `json:"disabled"`
+		Window   duration `json:"window"`
+		Score    int      `json:"score"`
+	} `json:"duplicates"`
+	Hook struct {
+		URL     string   `json:"url"`
+		Timeout duration `json:"timeout"`
+	} `json:"hook"`
+}
+
+// duration reads durations like "24h" from json.
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+const (
+	defaultHoldScore     = 10
+	defaultMaxLinks      = 2
+	defaultMinReputation = 10
+	defaultNewAccountAge = 72 * time.Hour
+	defaultWindow        = 24 * time.Hour
+	defaultHookTimeout   = 2 * time.Second
+)
+
+// FromEnv builds the pipeline from SCREENING_FILE. Without the file only
+// the link limit and the duplicate detection run, with their defaults.
+func FromEnv(history PostHistory) (*Pipeline, error) {
+	var config Config
+	if path := os.Getenv("SCREENING_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error during reading screening config: %v", err)
+		}
+		if err = json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("error during parsing screening config: %v", err)
+		}
+	}
+	return config.Pipeline(history)
+}
+
+// Pipeline builds the checks the config describes.
+func (c Config) Pipeline(history PostHistory) (*Pipeline, error) {
+	if c.HoldScore < 0 {
+		return nil, errors.New("hold_score can't be negative")
+	}
+	if c.HoldScore == 0 {
+		c.HoldScore = defaultHoldScore
+	}
+	score := func(s int) int {
+		if s == 0 {
+			return c.HoldScore
+		}
+		return s
+	}
+	pipeline := NewPipeline(c.HoldScore)
+
+	if len(c.BannedWords.Words) > 0 || len(c.BannedWords.Patterns) > 0 {
+		check, err := NewBannedWords(score(c.BannedWords.Score), c.BannedWords.Words, c.BannedWords.Patterns)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Use(check)
+	}
+	if !c.Links.Disabled {
+		check := &LinkLimit{
+			Score:         score(c.Links.Score),
+			MaxLinks:      defaultMaxLinks,
+			MinReputation: defaultMinReputation,
+			NewAccountAge: defaultNewAccountAge,
+		}
+		if c.Links.MaxLinks != nil {
+			check.MaxLinks = *c.Links.MaxLinks
+		}
+		if c.Links.MinReputation != nil {
+			check.MinReputation = *c.Links.MinReputation
+		}
+		if c.Links.NewAccountAge != 0 {
+			check.NewAccountAge = time.Duration(c.Links.NewAccountAge)
+		}
+		pipeline.Use(check)
+	}
+	if !c.Duplicates.Disabled {
+		check := &Duplicates{Score: score(c.Duplicates.Score), Window: defaultWindow, History: history}
+		if c.Duplicates.Window != 0 {
+			check.Window = time.Duration(c.Duplicates.Window)
+		}
+		pipeline.Use(check)
+	}
+	if c.Hook.URL != "" {
+		timeout := defaultHookTimeout
+		if c.Hook.Timeout != 0 {
+			timeout = time.Duration(c.Hook.Timeout)
+		}
+		pipeline.Use(&Hook{URL: c.Hook.URL, Client: &http.Client{Timeout: timeout}})
+	}
+	return pipeline, nil
+}
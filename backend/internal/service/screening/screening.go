@@ -0,0 +1,75 @@
+// Package screening looks at new discussions and comments before they are
+// stored and decides which of them a moderator should see first. Checks
+// add to the score of a post, posts reaching the hold score are held for
+// review instead of being published.
+package screening
+
+import (
+	"context"
+	"gohelp/internal/models"
+	"time"
+)
+
+// Post is what the checks see of a new discussion or comment. Title is
+// empty for comments.
+type Post struct {
+	Type       string    `json:"type"`
+	Title      string    `json:"title,omitempty"`
+	Content    string    `json:"content"`
+	AuthorID   int       `json:"author_id"`
+	Reputation int       `json:"reputation"`
+	JoinedAt   time.Time `json:"joined_at"`
+}
+
+// Result is what one check found. A zero score means nothing.
+type Result struct {
+	Score   int
+	Reasons []string
+}
+
+// Check is one step of the pipeline.
+type Check interface {
+	Check(ctx context.Context, post Post) (Result, error)
+}
+
+// CheckFunc lets a function be used as a Check.
+type CheckFunc func(ctx context.Context, post Post) (Result, error)
+
+func (f CheckFunc) Check(ctx context.Context, post Post) (Result, error) {
+	return f(ctx, post)
+}
+
+// Pipeline runs every check on a post and adds up their scores.
+type Pipeline struct {
+	holdScore int
+	checks    []Check
+}
+
+func NewPipeline(holdScore int, checks ...Check) *Pipeline {
+	return &Pipeline{holdScore: holdScore, checks: checks}
+}
+
+// Use adds checks to the end of the pipeline.
+func (p *Pipeline) Use(checks ...Check) {
+	p.checks = append(p.checks, checks...)
+}
+
+// Screen returns the verdict on the post, or nil when it can be published.
+func (p *Pipeline) Screen(ctx context.Context, post Post) (*models.Screening, error) {
+	verdict := models.Screening{Reasons: []string{}}
+	for _, check := range p.checks {
+		result, err := check.Check(ctx, post)
+		if err != nil {
+			return nil, err
+		}
+		if result.Score == 0 {
+			continue
+		}
+		verdict.Score += result.Score
+		verdict.Reasons = append(verdict.Reasons, result.Reasons...)
+	}
+	if verdict.Score < p.holdScore {
+		return nil, nil
+	}
+	return &verdict, nil
+}
@@ -0,0 +1,60 @@
+// Package migrate applies versioned schema changes to Postgres and Mongo
+// and records which of them are applied.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Migration struct {
+	Version int
+	Name    string
+}
+
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Runner migrates one database.
+type Runner interface {
+	Name() string
+	Status(ctx context.Context) ([]Status, error)
+	// Up applies every pending migration in order.
+	Up(ctx context.Context) ([]Migration, error)
+	// Down reverts the last steps applied migrations, newest first.
+	Down(ctx context.Context, steps int) ([]Migration, error)
+}
+
+// Pending returns the migrations of status that are not applied.
+func Pending(status []Status) []Migration {
+	var pending []Migration
+	for _, s := range status {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending
+}
+
+// CheckCurrent returns an error naming the pending migrations if some
+// database is behind.
+func CheckCurrent(ctx context.Context, runners ...Runner) error {
+	var behind []string
+	for _, r := range runners {
+		status, err := r.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("error during reading %s schema version: %v", r.Name(), err)
+		}
+		for _, m := range Pending(status) {
+			behind = append(behind, fmt.Sprintf("%s %04d_%s", r.Name(), m.Version, m.Name))
+		}
+	}
+	if len(behind) > 0 {
+		return fmt.Errorf("schema is out of date, pending migrations: %s", strings.Join(behind, ", "))
+	}
+	return nil
+}
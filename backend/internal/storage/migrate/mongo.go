@@ -0,0 +1,122 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoMigration changes collections or indexes of a Mongo database. Mongo
+// has no transactions for such changes, so Up and Down should be safe to
+// run again after a failure.
+type MongoMigration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+// Mongo applies MongoMigrations and keeps the applied versions in the
+// schema_migrations collection.
+type Mongo struct {
+	db         *mongo.Database
+	migrations []MongoMigration
+}
+
+func NewMongo(db *mongo.Database, migrations []MongoMigration) *Mongo {
+	sorted := append([]MongoMigration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return &Mongo{db: db, migrations: sorted}
+}
+
+func (m *Mongo) Name() string {
+	return "mongo"
+}
+
+func (m *Mongo) applied(ctx context.Context) (map[int]time.Time, error) {
+	cursor, err := m.db.Collection("schema_migrations").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var docs []struct {
+		Version   int       `bson:"_id"`
+		AppliedAt time.Time `bson:"applied_at"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]time.Time, len(docs))
+	for _, d := range docs {
+		applied[d.Version] = d.AppliedAt
+	}
+	return applied, nil
+}
+
+func (m *Mongo) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Migration: Migration{Version: mig.Version, Name: mig.Name}}
+		if at, ok := applied[mig.Version]; ok {
+			s.AppliedAt = &at
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+func (m *Mongo) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if err = mig.Up(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %04d_%s failed: %v", mig.Version, mig.Name, err)
+		}
+		_, err = m.db.Collection("schema_migrations").InsertOne(ctx, bson.M{
+			"_id":        mig.Version,
+			"name":       mig.Name,
+			"applied_at": time.Now(),
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, Migration{Version: mig.Version, Name: mig.Name})
+	}
+	return done, nil
+}
+
+func (m *Mongo) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if err = mig.Down(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %04d_%s failed: %v", mig.Version, mig.Name, err)
+		}
+		if _, err = m.db.Collection("schema_migrations").DeleteOne(ctx, bson.M{"_id": mig.Version}); err != nil {
+			return done, err
+		}
+		done = append(done, Migration{Version: mig.Version, Name: mig.Name})
+	}
+	return done, nil
+}
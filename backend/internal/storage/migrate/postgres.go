@@ -0,0 +1,169 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var sqlFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type sqlMigration struct {
+	Migration
+	up   string
+	down string
+}
+
+// Postgres applies the NNNN_name.up.sql and NNNN_name.down.sql files of a
+// directory and keeps the applied versions in schema_migrations.
+type Postgres struct {
+	db         *sqlx.DB
+	migrations []sqlMigration
+}
+
+func NewPostgres(db *sqlx.DB, files fs.FS) (*Postgres, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*sqlMigration)
+	for _, entry := range entries {
+		match := sqlFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &sqlMigration{Migration: Migration{Version: version, Name: match[2]}}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+		}
+	}
+	p := &Postgres{db: db}
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both up and down files", m.Version, m.Name)
+		}
+		p.migrations = append(p.migrations, *m)
+	}
+	sort.Slice(p.migrations, func(i, j int) bool { return p.migrations[i].Version < p.migrations[j].Version })
+	return p, nil
+}
+
+func (p *Postgres) Name() string {
+	return "postgres"
+}
+
+func (p *Postgres) applied(ctx context.Context) (map[int]time.Time, error) {
+	_, err := p.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER     PRIMARY KEY,
+		name       TEXT        NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := p.db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+func (p *Postgres) Status(ctx context.Context) ([]Status, error) {
+	applied, err := p.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]Status, 0, len(p.migrations))
+	for _, m := range p.migrations {
+		s := Status{Migration: m.Migration}
+		if at, ok := applied[m.Version]; ok {
+			s.AppliedAt = &at
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// run executes the script of m and records the change in one transaction.
+func (p *Postgres) run(ctx context.Context, script, record string, m Migration) error {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %v", m.Version, m.Name, err)
+	}
+	if _, err = tx.ExecContext(ctx, record, m.Version, m.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := p.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, m := range p.migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err = p.run(ctx, m.up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Migration)
+		if err != nil {
+			return done, err
+		}
+		done = append(done, m.Migration)
+	}
+	return done, nil
+}
+
+func (p *Postgres) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := p.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for i := len(p.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := p.migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		err = p.run(ctx, m.down, "DELETE FROM schema_migrations WHERE version = $1 AND name = $2", m.Migration)
+		if err != nil {
+			return done, err
+		}
+		done = append(done, m.Migration)
+	}
+	return done, nil
+}
@@ -0,0 +1,80 @@
+package mongo
+
+import (
+	"context"
+	"gohelp/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CountAuthorContent returns how many discussions and comments of the user
+// are not deleted.
+func (s *ForumStorage) CountAuthorContent(ctx context.Context, userID int) (int64, int64, error) {
+	filter := bson.M{"author_id": userID, "deleted": false}
+	discussions, err := s.discussions.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, 0, err
+	}
+	comments, err := s.comments.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, 0, err
+	}
+	return discussions, comments, nil
+}
+
+// findAuthorPage loads one page of not deleted documents of the user from
+// coll, newest first, into result.
+func findAuthorPage(ctx context.Context, coll *mongo.Collection, userID, limit int, after string, result interface{}) error {
+	filter := bson.M{"author_id": userID, "deleted": false}
+	if after != "" {
+		c, oid, err := decodeCursor(after, models.SortNewest)
+		if err != nil {
+			return err
+		}
+		filter = bson.M{"$and": []bson.M{filter, afterCursor("created_at", c.Time, oid)}}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit + 1))
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, result)
+}
+
+func (s *ForumStorage) GetDiscussionsByAuthor(ctx context.Context, userID, limit int, after string) ([]models.DiscussionTopic, string, error) {
+	discussions := []models.DiscussionTopic{}
+	if err := findAuthorPage(ctx, s.discussions, userID, limit, after, &discussions); err != nil {
+		return nil, "", err
+	}
+	nextCursor := ""
+	if len(discussions) > limit {
+		discussions = discussions[:limit]
+		last := discussions[len(discussions)-1]
+		nextCursor = encodeCursor(pageCursor{Sort: models.SortNewest, ID: last.ID, Time: last.CreatedAt})
+	}
+	return discussions, nextCursor, nil
+}
+
+func (s *ForumStorage) GetCommentsByAuthor(ctx context.Context, userID, limit int, after string) ([]models.Comment, string, error) {
+	comments := []models.Comment{}
+	if err := findAuthorPage(ctx, s.comments, userID, limit, after, &comments); err != nil {
+		return nil, "", err
+	}
+	nextCursor := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[len(comments)-1]
+		nextCursor = encodeCursor(pageCursor{Sort: models.SortNewest, ID: last.ID, Time: last.CreatedAt})
+	}
+	for i := range comments {
+		comments[i].LikesCount = len(comments[i].Likes)
+		comments[i].DisikesCount = len(comments[i].Dislikes)
+	}
+	return comments, nextCursor, nil
+}
@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is the position of the last element of a page. It is sent to
+// clients as base64 encoded json, so they should treat it as opaque.
+type pageCursor struct {
+	Sort  string    `json:"s"`
+	ID    string    `json:"id"`
+	Count int64     `json:"c,omitempty"`
+	Time  time.Time `json:"t,omitempty"`
+}
+
+func encodeCursor(c pageCursor) string {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s, sort string) (*pageCursor, primitive.ObjectID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, primitive.NilObjectID, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, primitive.NilObjectID, ErrInvalidCursor
+	}
+	if c.Sort != sort {
+		return nil, primitive.NilObjectID, ErrInvalidCursor
+	}
+	oid, err := primitive.ObjectIDFromHex(c.ID)
+	if err != nil {
+		return nil, primitive.NilObjectID, ErrInvalidCursor
+	}
+	return &c, oid, nil
+}
+
+// afterCursor matches documents placed after the cursor when sorting by
+// field and _id, both descending.
+func afterCursor(field string, value interface{}, oid primitive.ObjectID) bson.M {
+	return bson.M{
+		"$or": []bson.M{
+			{field: bson.M{"$lt": value}},
+			{field: value, "_id": bson.M{"$lt": oid}},
+		},
+	}
+}
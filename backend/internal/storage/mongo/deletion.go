@@ -0,0 +1,97 @@
+package mongo
+
+import (
+	"context"
+	"gohelp/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// deletion deletes documents and records why: the cause, who deleted them
+// and the sanction they went away with. Restores pick the documents to
+// bring back by these fields, so content deleted for another cause stays
+// deleted.
+func deletion(cause string, deletedBy int, sanctionID int64) bson.M {
+	set := bson.M{"deleted": true, "deleted_cause": cause}
+	if deletedBy != 0 {
+		set["deleted_by"] = deletedBy
+	}
+	if sanctionID != 0 {
+		set["sanction_id"] = sanctionID
+	}
+	return bson.M{"$set": set}
+}
+
+// undeletion brings documents back and forgets why they were deleted.
+var undeletion = bson.M{
+	"$set":   bson.M{"deleted": false},
+	"$unset": bson.M{"deleted_cause": "", "deleted_by": "", "sanction_id": ""},
+}
+
+// RestoreSanctioned brings back the content the sanctions hid, that is the
+// content of the user deleted as a ban cascade and the comments of others
+// hidden with their discussions, and returns the reputation it gives
+// again. Comments of the user were taken out of comments_count when they
+// were deleted, comments of others went away only with a discussion of the
+// user, so only the former are counted back in.
+func (s *ForumStorage) RestoreSanctioned(ctx context.Context, userID int, sanctionIDs []int64) (map[int]int, error) {
+	hidden := bson.M{
+		"deleted":       true,
+		"sanction_id":   bson.M{"$in": sanctionIDs},
+		"deleted_cause": bson.M{"$in": bson.A{models.DeletedByBan, models.DeletedWithDiscussion}},
+	}
+
+	cursor, err := s.comments.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"$and": bson.A{hidden, bson.M{"author_id": userID, "deleted_cause": models.DeletedByBan}}}},
+		bson.M{"$group": bson.M{"_id": "$discussion_id", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var counts []struct {
+		DiscussionID string `bson:"_id"`
+		Count        int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	// The tag stays until the reputation of the restored content is known.
+	restore := bson.M{"$set": bson.M{"deleted": false}}
+	if _, err = s.discussions.UpdateMany(ctx, hidden, restore); err != nil {
+		return nil, err
+	}
+	if _, err = s.comments.UpdateMany(ctx, hidden, restore); err != nil {
+		return nil, err
+	}
+	restored := make(map[string]int, len(counts))
+	for _, c := range counts {
+		restored[c.DiscussionID] = -c.Count
+	}
+	if err = s.decCommentsCount(ctx, restored); err != nil {
+		return nil, err
+	}
+
+	back := bson.M{
+		"deleted":       false,
+		"sanction_id":   bson.M{"$in": sanctionIDs},
+		"deleted_cause": bson.M{"$in": bson.A{models.DeletedByBan, models.DeletedWithDiscussion}},
+	}
+	scores, err := s.reputationScores(ctx, reputationScope{
+		discussions: back,
+		comments:    back,
+		accepted:    bson.M{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.discussions.UpdateMany(ctx, back, undeletion); err != nil {
+		return nil, err
+	}
+	if _, err = s.comments.UpdateMany(ctx, back, undeletion); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
@@ -0,0 +1,122 @@
+package mongo
+
+import (
+	"context"
+	"gohelp/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sortFeed names the order of the feed in its cursors.
+const sortFeed = "feed"
+
+// feedFilter matches the visible posts of others that one of the
+// conditions selects, after the cursor if there is one. It is nil when
+// there are no conditions.
+func feedFilter(userID int, or []bson.M, after bson.M) bson.M {
+	if len(or) == 0 {
+		return nil
+	}
+	and := []bson.M{{"deleted": false, "author_id": bson.M{"$ne": userID}}, {"$or": or}}
+	if after != nil {
+		and = append(and, after)
+	}
+	return bson.M{"$and": and}
+}
+
+// findFeed loads up to limit posts matching the filter from coll, the
+// latest first, into result. A nil filter loads nothing.
+func findFeed(ctx context.Context, coll *mongo.Collection, filter bson.M, limit int, result interface{}) error {
+	if filter == nil {
+		return nil
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, result)
+}
+
+// GetFeed returns one page of the feed: discussions with a followed tag or
+// by a followed user, and comments in a followed discussion or by a
+// followed user, the latest first. Posts of the user are left out.
+func (s *ForumStorage) GetFeed(ctx context.Context, query models.FeedQuery) ([]models.FeedItem, string, error) {
+	var after bson.M
+	if query.Cursor != "" {
+		c, oid, err := decodeCursor(query.Cursor, sortFeed)
+		if err != nil {
+			return nil, "", err
+		}
+		after = afterCursor("created_at", c.Time, oid)
+	}
+	var discussionsOr, commentsOr []bson.M
+	if len(query.Tags) > 0 {
+		discussionsOr = append(discussionsOr, bson.M{"tags": bson.M{"$in": query.Tags}})
+	}
+	if len(query.Discussions) > 0 {
+		commentsOr = append(commentsOr, bson.M{"discussion_id": bson.M{"$in": query.Discussions}})
+	}
+	if len(query.Users) > 0 {
+		byUsers := bson.M{"author_id": bson.M{"$in": query.Users}}
+		discussionsOr = append(discussionsOr, byUsers)
+		commentsOr = append(commentsOr, byUsers)
+	}
+
+	var discussions []models.Discussion
+	if err := findFeed(ctx, s.discussions, feedFilter(query.UserID, discussionsOr, after), query.Limit+1, &discussions); err != nil {
+		return nil, "", err
+	}
+	var comments []models.Comment
+	if err := findFeed(ctx, s.comments, feedFilter(query.UserID, commentsOr, after), query.Limit+1, &comments); err != nil {
+		return nil, "", err
+	}
+
+	// Both lists are sorted the same way, ids of the same length compare
+	// in hex as they do in bytes.
+	items := make([]models.FeedItem, 0, len(discussions)+len(comments))
+	for i, j := 0, 0; i < len(discussions) || j < len(comments); {
+		if j == len(comments) || (i < len(discussions) && feedBefore(discussions[i].CreatedAt, discussions[i].ID, comments[j].CreatedAt, comments[j].ID)) {
+			d := discussions[i]
+			items = append(items, models.FeedItem{
+				Type:      models.PostDiscussion,
+				ID:        d.ID,
+				Title:     d.Title,
+				Content:   d.Content,
+				Tags:      d.Tags,
+				AuthorID:  d.AuthorID,
+				CreatedAt: d.CreatedAt,
+			})
+			i++
+		} else {
+			c := comments[j]
+			items = append(items, models.FeedItem{
+				Type:         models.PostComment,
+				ID:           c.ID,
+				DiscussionID: c.DiscussionID,
+				Content:      c.Content,
+				AuthorID:     c.AuthorID,
+				CreatedAt:    c.CreatedAt,
+			})
+			j++
+		}
+	}
+	if len(items) <= query.Limit {
+		return items, "", nil
+	}
+	items = items[:query.Limit]
+	last := items[len(items)-1]
+	return items, encodeCursor(pageCursor{Sort: sortFeed, ID: last.ID, Time: last.CreatedAt}), nil
+}
+
+// feedBefore tells if the first post comes before the second one in the
+// feed.
+func feedBefore(firstAt time.Time, firstID string, secondAt time.Time, secondID string) bool {
+	return firstAt.After(secondAt) || (firstAt.Equal(secondAt) && firstID > secondID)
+}
@@ -14,9 +14,15 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrNotFound is returned when the requested document does not exist, is
+// deleted or its id is not a valid ObjectID.
+var ErrNotFound = errors.New("document not found")
+
 type ForumStorage struct {
 	discussions *mongo.Collection
 	comments    *mongo.Collection
+	tags        *mongo.Collection
+	revisions   *mongo.Collection
 	client      *mongo.Client
 }
 
@@ -24,6 +30,8 @@ func NewForumStorage(db *mongo.Database, client *mongo.Client) *ForumStorage {
 	return &ForumStorage{
 		discussions: db.Collection("discussions"),
 		comments:    db.Collection("comments"),
+		tags:        db.Collection("tags"),
+		revisions:   db.Collection("revisions"),
 		client: client,
 	}
 }
@@ -36,7 +44,11 @@ func (s *ForumStorage) CreateDiscussion(ctx context.Context, discussion *models.
 	if discussion.Dislikes == nil {
 		discussion.Dislikes = []int{}
 	}
-	discussion.Deleted = false
+	if discussion.Tags == nil {
+		discussion.Tags = []string{}
+	}
+	discussion.LastActivityAt = discussion.CreatedAt
+	discussion.Deleted, discussion.DeletedCause = holding(discussion.Screening)
 	res, err := s.discussions.InsertOne(ctx, discussion)
 	if err != nil {
 		return "", err
@@ -48,7 +60,7 @@ func (s *ForumStorage) CreateDiscussion(ctx context.Context, discussion *models.
 func (s *ForumStorage) GetDiscussion(ctx context.Context, id string) (*models.Discussion, error) {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid ID format")
+		return nil, ErrNotFound
 	}
 	var discussion models.Discussion
 	err = s.discussions.FindOne(ctx, bson.M{
@@ -57,6 +69,9 @@ func (s *ForumStorage) GetDiscussion(ctx context.Context, id string) (*models.Di
 			{"deleted": false},
 		},
 	}).Decode(&discussion)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +85,7 @@ func (s *ForumStorage) GetDiscussion(ctx context.Context, id string) (*models.Di
 func (s *ForumStorage) GetComment(ctx context.Context, id string) (*models.Comment, error) {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid ID format")
+		return nil, ErrNotFound
 	}
 
 	var comments models.Comment
@@ -80,6 +95,9 @@ func (s *ForumStorage) GetComment(ctx context.Context, id string) (*models.Comme
 			{"deleted": false},
 		},
 	}).Decode(&comments)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -90,23 +108,138 @@ func (s *ForumStorage) GetComment(ctx context.Context, id string) (*models.Comme
 	return &comments, nil
 }
 
-func (s *ForumStorage) GetAllDiscussions(ctx context.Context) ([]models.DiscussionTopic, error) {
-	var discussions []models.DiscussionTopic
+func discussionFilter(f models.DiscussionFilter) bson.M {
 	filter := bson.M{"deleted": false}
-	cursor, err := s.discussions.Find(context.TODO(), filter)
+	if len(f.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": f.Tags}
+	}
+	if f.Resolved != nil {
+		// discussions created before answers could be accepted have no
+		// resolved field at all
+		if *f.Resolved {
+			filter["resolved"] = true
+		} else {
+			filter["resolved"] = bson.M{"$ne": true}
+		}
+	}
+	return filter
+}
+
+var sortFields = map[string]string{
+	models.SortNewest:         "created_at",
+	models.SortMostLiked:      "likes_count",
+	models.SortMostCommented:  "comments_count",
+	models.SortRecentlyActive: "last_activity_at",
+}
+
+func (s *ForumStorage) GetDiscussionsPage(ctx context.Context, query models.DiscussionListQuery) ([]models.DiscussionWithCount, string, error) {
+	field, ok := sortFields[query.Sort]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown sort: %v", query.Sort)
+	}
+
+	filter := discussionFilter(query.DiscussionFilter)
+	if query.Cursor != "" {
+		c, oid, err := decodeCursor(query.Cursor, query.Sort)
+		if err != nil {
+			return nil, "", err
+		}
+		switch query.Sort {
+		case models.SortMostLiked, models.SortMostCommented:
+			filter = bson.M{"$and": []bson.M{filter, afterCursor(field, c.Count, oid)}}
+		default:
+			filter = bson.M{"$and": []bson.M{filter, afterCursor(field, c.Time, oid)}}
+		}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: field, Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(query.Limit + 1)).
+		SetProjection(bson.M{"likes": 0, "dislikes": 0})
+
+	cursor, err := s.discussions.Find(ctx, filter, opts)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer cursor.Close(ctx)
 
+	var discussions []models.DiscussionTopic
 	if err = cursor.All(ctx, &discussions); err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(discussions) > query.Limit {
+		discussions = discussions[:query.Limit]
+		last := discussions[len(discussions)-1]
+		c := pageCursor{Sort: query.Sort, ID: last.ID}
+		switch query.Sort {
+		case models.SortNewest:
+			c.Time = last.CreatedAt
+		case models.SortMostLiked:
+			c.Count = int64(last.LikesCount)
+		case models.SortMostCommented:
+			c.Count = last.CommentsCount
+		case models.SortRecentlyActive:
+			c.Time = last.LastActivityAt
+		}
+		nextCursor = encodeCursor(c)
 	}
-	for i := range discussions {
-		discussions[i].LikesCount = len(discussions[i].Likes)
-		discussions[i].DisikesCount = len(discussions[i].Dislikes)
+
+	result := make([]models.DiscussionWithCount, 0, len(discussions))
+	for _, discussion := range discussions {
+		result = append(result, models.DiscussionWithCount{
+			Discussion:     discussion,
+			CommentsCount:  discussion.CommentsCount,
+			LastActivityAt: discussion.LastActivityAt,
+		})
 	}
-	return discussions, nil
+	return result, nextCursor, nil
+}
+
+// BackfillDiscussionCounters fills comments_count, likes_count,
+// dislikes_count and last_activity_at for discussions created before these
+// fields were kept on the document. It is done by one aggregation that
+// merges the computed values back into the discussions collection.
+func (s *ForumStorage) BackfillDiscussionCounters(ctx context.Context) error {
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"comments_count": bson.M{"$exists": false}}},
+		bson.M{"$lookup": bson.M{
+			"from": "comments",
+			"let":  bson.M{"id": bson.M{"$toString": "$_id"}},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$discussion_id", "$$id"}},
+					bson.M{"$eq": bson.A{"$deleted", false}},
+				}}}},
+				bson.M{"$group": bson.M{
+					"_id":   nil,
+					"count": bson.M{"$sum": 1},
+					"last":  bson.M{"$max": "$created_at"},
+				}},
+			},
+			"as": "stats",
+		}},
+		bson.M{"$project": bson.M{
+			"likes_count":    bson.M{"$size": "$likes"},
+			"dislikes_count": bson.M{"$size": "$dislikes"},
+			"comments_count": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$stats.count", 0}}, 0}},
+			"last_activity_at": bson.M{"$max": bson.A{
+				"$created_at",
+				bson.M{"$arrayElemAt": bson.A{"$stats.last", 0}},
+			}},
+		}},
+		bson.M{"$merge": bson.M{
+			"into":           "discussions",
+			"on":             "_id",
+			"whenMatched":    "merge",
+			"whenNotMatched": "discard",
+		}},
+	}
+	cursor, err := s.discussions.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
 }
 
 func (s *ForumStorage) CreateComment(ctx context.Context, comment *models.Comment) (string, error) {
@@ -117,42 +250,76 @@ func (s *ForumStorage) CreateComment(ctx context.Context, comment *models.Commen
 	if comment.Dislikes == nil {
 		comment.Dislikes = []int{}
 	}
-	comment.Deleted = false
-	fmt.Println(comment)
+	comment.Deleted, comment.DeletedCause = holding(comment.Screening)
+	discussionOID, err := primitive.ObjectIDFromHex(comment.DiscussionID)
+	if err != nil {
+		return "", errors.New("invalid discussionID")
+	}
 	res, err := s.comments.InsertOne(ctx, comment)
 	if err != nil {
 		return "", err
 	}
-	id := res.InsertedID.(primitive.ObjectID).Hex()
-	return id, nil
-}
-
-func (s *ForumStorage) GetSummaryOfDiscussions(ctx context.Context, discussions []models.DiscussionTopic) ([]models.DiscussionWithCount, error) {
-	var result []models.DiscussionWithCount
+	commentOID := res.InsertedID.(primitive.ObjectID)
+	// A held comment counts once it is approved.
+	if comment.Deleted {
+		return commentOID.Hex(), nil
+	}
 
-	for _, discussion := range discussions {
-		log.Println(discussion.ID)
-		count, err := s.comments.CountDocuments(context.TODO(), bson.M{
-			"$and": []bson.M{
-				{"discussion_id": discussion.ID},
-				{"deleted": false},
-			},
-		})
-		if err != nil {
-			return nil, err
+	// A comment the discussion does not count is taken back, so that a
+	// failed request leaves nothing behind to be posted twice on retry.
+	updated, err := s.discussions.UpdateOne(ctx, bson.M{"_id": discussionOID}, bson.M{
+		"$inc": bson.M{"comments_count": 1},
+		"$max": bson.M{"last_activity_at": comment.CreatedAt},
+	})
+	if err == nil && updated.MatchedCount == 0 {
+		err = ErrNotFound
+	}
+	if err != nil {
+		if _, delErr := s.comments.DeleteOne(ctx, bson.M{"_id": commentOID}); delErr != nil {
+			return "", fmt.Errorf("%v, and the comment was not taken back: %v", err, delErr)
 		}
+		return "", err
+	}
+	return commentOID.Hex(), nil
+}
 
-		result = append(result, models.DiscussionWithCount{
-			Discussion:    discussion,
-			CommentsCount: count,
-		})
+// SetAcceptedAnswer marks commentID as the accepted answer of the
+// discussion. An empty commentID clears the choice.
+func (s *ForumStorage) SetAcceptedAnswer(ctx context.Context, discussionID, commentID string) error {
+	oid, err := primitive.ObjectIDFromHex(discussionID)
+	if err != nil {
+		return errors.New("invalid discussionID")
 	}
-	return result, nil
+	_, err = s.discussions.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$set": bson.M{
+			"accepted_answer_id": commentID,
+			"resolved":           commentID != "",
+		},
+	})
+	return err
+}
+
+// ClearAcceptedAnswer reopens the discussion whose accepted answer is
+// commentID, if there is one.
+func (s *ForumStorage) ClearAcceptedAnswer(ctx context.Context, commentID string) error {
+	_, err := s.discussions.UpdateOne(ctx, bson.M{"accepted_answer_id": commentID}, bson.M{
+		"$set": bson.M{
+			"accepted_answer_id": "",
+			"resolved":           false,
+		},
+	})
+	return err
 }
 
+// GetCommentsByDiscussion returns the comments of the discussion. Deleted
+// comments stay as placeholders of their replies, comments waiting for
+// review or rejected in it are left out.
 func (s *ForumStorage) GetCommentsByDiscussion(ctx context.Context, discussionID string) ([]models.Comment, error) {
 	var comments []models.Comment
-	cursor, err := s.comments.Find(context.TODO(), bson.M{"discussion_id": discussionID})
+	cursor, err := s.comments.Find(context.TODO(), bson.M{
+		"discussion_id": discussionID,
+		"deleted_cause": bson.M{"$nin": []string{models.HeldForReview, models.RejectedInReview}},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -168,14 +335,13 @@ func (s *ForumStorage) GetCommentsByDiscussion(ctx context.Context, discussionID
 	return comments, nil
 }
 
-func (s *ForumStorage) SearchDiscussionsByName(ctx context.Context, searchTerm string) ([]models.Discussion, error) {
+func (s *ForumStorage) SearchDiscussionsByName(ctx context.Context, searchTerm string, f models.DiscussionFilter) ([]models.Discussion, error) {
 
-	filter := bson.M{
-		"$text": bson.M{
-			"$search": searchTerm,
-		},
+	filter := discussionFilter(f)
+	filter["$text"] = bson.M{
+		"$search": searchTerm,
 	}
-	opts := options.Find().SetSort(bson.D{{"score", bson.M{"$meta": "textScore"}}})
+	opts := options.Find().SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}})
 
 	cursor, err := s.discussions.Find(context.TODO(), filter, opts)
 	if err != nil {
@@ -191,158 +357,114 @@ func (s *ForumStorage) SearchDiscussionsByName(ctx context.Context, searchTerm s
 	return discussions, nil
 }
 
-func (s *ForumStorage) RemoveVote(userID int, discussionID string, voteType string) error {
-	oid, err := primitive.ObjectIDFromHex(discussionID)
-	if err != nil {
-		return errors.New("invalid discussionID")
-	}
-	filter := bson.M{"_id": oid}
-	removeVote := bson.M{
-		"$pull": bson.M{
-			"likes":    userID,
-			"dislikes": userID,
-		},
-	}
-	_, err = s.discussions.UpdateOne(context.TODO(), filter, removeVote)
-	if err != nil {
-		return err
-	}
-	return nil
+// voteCounts keeps likes_count and dislikes_count of a discussion equal to
+// the size of its vote arrays. It is the last stage of every vote update.
+var voteCounts = bson.M{
+	"$set": bson.M{
+		"likes_count":    bson.M{"$size": "$likes"},
+		"dislikes_count": bson.M{"$size": "$dislikes"},
+	},
 }
-func (s *ForumStorage) DiscAddVote(userID int, discussionID string, voteType string) error {
-	oid, err := primitive.ObjectIDFromHex(discussionID)
-	if err != nil {
-		return errors.New("invalid discussionID")
-	}
-	filter := bson.M{"_id": oid}
-	var update bson.M
-	if voteType == "like" {
-		fmt.Println("\nlike")
-		update = bson.M{
-			"$push": bson.M{
-				"likes": userID,
-			},
-		}
-	} else if voteType == "dislike" {
-		fmt.Println("dislike")
-		update = bson.M{
-			"$push": bson.M{
-				"dislikes": userID,
-			},
-		}
-	}
 
-	result, err := s.discussions.UpdateOne(context.TODO(), filter, update)
-	fmt.Println(result)
-	if err != nil {
-		return err
-	}
-	return nil
+func withoutVoter(field string, userID int) bson.M {
+	return bson.M{"$filter": bson.M{
+		"input": "$" + field,
+		"cond":  bson.M{"$ne": bson.A{"$$this", userID}},
+	}}
 }
 
-func (s *ForumStorage) ComAddVote(userID int, commentID string, voteType string) error {
-	oid, err := primitive.ObjectIDFromHex(commentID)
-	if err != nil {
-		return errors.New("invalid discussionID")
-	}
-	filter := bson.M{"_id": oid}
-	var update bson.M
-	if voteType == "like" {
-		fmt.Println("\nlike")
-		update = bson.M{
-			"$push": bson.M{
-				"likes": userID,
-			},
-		}
-	} else if voteType == "dislike" {
-		fmt.Println("dislike")
-		update = bson.M{
-			"$push": bson.M{
-				"dislikes": userID,
-			},
-		}
-	}
+// voteUpdate replaces the vote of the user with voteType, "none" only
+// withdraws it. Both arrays are rebuilt by one stage, so the user ends up
+// in at most one of them however many votes run at once.
+func voteUpdate(userID int, voteType string) (bson.M, error) {
+	likes, dislikes := withoutVoter("likes", userID), withoutVoter("dislikes", userID)
+	switch voteType {
+	case "like":
+		likes = bson.M{"$concatArrays": bson.A{likes, bson.A{userID}}}
+	case "dislike":
+		dislikes = bson.M{"$concatArrays": bson.A{dislikes, bson.A{userID}}}
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown vote type: %v", voteType)
+	}
+	return bson.M{"$set": bson.M{"likes": likes, "dislikes": dislikes}}, nil
+}
 
-	result, err := s.comments.UpdateOne(context.TODO(), filter, update)
-	fmt.Println(result)
+// castVote applies the vote to the post in coll, followed by the stages,
+// and decodes the post as it was right before into before.
+func castVote(ctx context.Context, coll *mongo.Collection, postID string, userID int, voteType string, before interface{}, stages ...bson.M) error {
+	oid, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
-		return err
+		return ErrNotFound
 	}
-	return nil
-}
-
-func (s *ForumStorage) UpdateDiscussion(ctx context.Context, discussionID, content string) error {
-	oid, err := primitive.ObjectIDFromHex(discussionID)
+	vote, err := voteUpdate(userID, voteType)
 	if err != nil {
 		return err
 	}
-
-	filter := bson.M{"_id": oid}
-
-	update := bson.M{
-		"$set": bson.M{
-			"content": content,
-			"edited":  true,
-		},
+	update := bson.A{vote}
+	for _, stage := range stages {
+		update = append(update, stage)
 	}
-
-	result, err := s.discussions.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
+	err = coll.FindOneAndUpdate(ctx, bson.M{"_id": oid, "deleted": false}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.Before)).Decode(before)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
 	}
-	log.Println(result)
-
-	return nil
+	return err
 }
 
-func (s *ForumStorage) UpdateComment(ctx context.Context, commentID, content string) error {
-	oid, err := primitive.ObjectIDFromHex(commentID)
-	if err != nil {
-		return err
+// VoteDiscussion replaces the vote of the user on the discussion and
+// returns the discussion as it was before the vote.
+func (s *ForumStorage) VoteDiscussion(ctx context.Context, discussionID string, userID int, voteType string) (*models.Discussion, error) {
+	var before models.Discussion
+	if err := castVote(ctx, s.discussions, discussionID, userID, voteType, &before, voteCounts); err != nil {
+		return nil, err
 	}
+	return &before, nil
+}
 
-	filter := bson.M{"_id": oid}
-
-	update := bson.M{
-		"$set": bson.M{
-			"content": content,
-			"edited":  true,
-		},
+// VoteComment replaces the vote of the user on the comment and returns the
+// comment as it was before the vote.
+func (s *ForumStorage) VoteComment(ctx context.Context, commentID string, userID int, voteType string) (*models.Comment, error) {
+	var before models.Comment
+	if err := castVote(ctx, s.comments, commentID, userID, voteType, &before); err != nil {
+		return nil, err
 	}
+	return &before, nil
+}
 
-	result, err := s.comments.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
-	}
-	log.Println(result)
+// UpdateDiscussion replaces the content of the discussion and keeps the
+// previous content as a revision. A non-nil screening holds the discussion
+// for review.
+func (s *ForumStorage) UpdateDiscussion(ctx context.Context, discussionID, content string, editorID int, screening *models.Screening) error {
+	return s.editPost(ctx, s.discussions, models.PostDiscussion, discussionID, content, editorID, screening)
+}
 
-	return nil
+// UpdateComment replaces the content of the comment and keeps the previous
+// content as a revision. A non-nil screening holds the comment for review.
+func (s *ForumStorage) UpdateComment(ctx context.Context, commentID, content string, editorID int, screening *models.Screening) error {
+	return s.editPost(ctx, s.comments, models.PostComment, commentID, content, editorID, screening)
 }
 
-func (s *ForumStorage) DeleteFullDiscussion(ctx context.Context, discussionID string) error {
+// DeleteFullDiscussion deletes the discussion for the cause and hides its
+// comments with it. Comments deleted before keep their own cause.
+func (s *ForumStorage) DeleteFullDiscussion(ctx context.Context, discussionID, cause string, deletedBy int) error {
 	oid, err := primitive.ObjectIDFromHex(discussionID)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": oid}
+	filter := bson.M{"_id": oid, "deleted": false}
 
-	update := bson.M{
-		"$set": bson.M{
-			"deleted": true,
-		},
-	}
-	
-
-	result, err := s.discussions.UpdateOne(ctx, filter, update)
+	result, err := s.discussions.UpdateOne(ctx, filter, deletion(cause, deletedBy, 0))
 	if err != nil {
 		return err
 	}
 	log.Println(result)
 
-	filter = bson.M{"discussion_id": discussionID}
+	filter = bson.M{"discussion_id": discussionID, "deleted": false}
 
-	result, err = s.comments.UpdateMany(ctx, filter, update)
+	result, err = s.comments.UpdateMany(ctx, filter, deletion(models.DeletedWithDiscussion, deletedBy, 0))
 	if err != nil {
 		return err
 	}
@@ -390,63 +512,110 @@ func (s *ForumStorage) DeleteFullDiscussion(ctx context.Context, discussionID st
 	return nil
 }
 
-func (s *ForumStorage) DeleteComment(ctx context.Context, commentID string) error {
+func (s *ForumStorage) DeleteComment(ctx context.Context, commentID, cause string, deletedBy int) error {
 	oid, err := primitive.ObjectIDFromHex(commentID)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": oid}
+	filter := bson.M{"_id": oid, "deleted": false}
 
-	update := bson.M{
-		"$set": bson.M{
-			"deleted": true,
-		},
-	}
+	update := deletion(cause, deletedBy, 0)
 
-	result, err := s.comments.UpdateOne(ctx, filter, update)
+	var comment models.Comment
+	err = s.comments.FindOneAndUpdate(ctx, filter, update).Decode(&comment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	log.Println(result)
 
-	return nil
+	return s.decCommentsCount(ctx, map[string]int{comment.DiscussionID: 1})
 }
 
-func (s *ForumStorage) DeleteAllComments(ctx context.Context, userID int) error {
-	filter := bson.M{"author_id": userID}
-
-	update := bson.M{
-		"$set": bson.M{
-			"deleted": true,
-		},
-	}
-
-	result, err := s.comments.UpdateMany(ctx, filter, update)
+// DeleteAllComments deletes every comment of the user as a cascade of the
+// sanction. The deleted comments are tagged uncounted until comments_count
+// of their discussions is decreased, so the counts come from the comments
+// this update deleted and not from a read made around it. Calling it again
+// for the sanction finishes the counting a failed call left.
+func (s *ForumStorage) DeleteAllComments(ctx context.Context, userID int, sanctionID int64) error {
+	update := deletion(models.DeletedByBan, 0, sanctionID)
+	update["$set"].(bson.M)["uncounted"] = true
+	_, err := s.comments.UpdateMany(ctx, bson.M{"author_id": userID, "deleted": false}, update)
 	if err != nil {
 		return err
 	}
-	log.Println(result)
-	return nil
-}
 
-func (s *ForumStorage) DeleteAllDiscussions(ctx context.Context, userID int) error {
-	var discussions []models.DiscussionTopic
-	filter := bson.M{"author_id": userID}
-	cursor, err := s.discussions.Find(context.TODO(), filter)
+	uncounted := bson.M{"author_id": userID, "sanction_id": sanctionID, "uncounted": true}
+	cursor, err := s.comments.Aggregate(ctx, bson.A{
+		bson.M{"$match": uncounted},
+		bson.M{"$group": bson.M{"_id": "$discussion_id", "count": bson.M{"$sum": 1}}},
+	})
 	if err != nil {
 		return err
 	}
 	defer cursor.Close(ctx)
-
-	if err = cursor.All(ctx, &discussions); err != nil {
+	var counts []struct {
+		DiscussionID string `bson:"_id"`
+		Count        int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &counts); err != nil {
 		return err
 	}
-	for _, discussion := range discussions {
-		s.DeleteFullDiscussion(ctx, discussion.ID)
+
+	removed := make(map[string]int, len(counts))
+	for _, c := range counts {
+		removed[c.DiscussionID] = c.Count
 	}
-	
-	return nil
+	if err = s.decCommentsCount(ctx, removed); err != nil {
+		return err
+	}
+	_, err = s.comments.UpdateMany(ctx, uncounted, bson.M{"$unset": bson.M{"uncounted": ""}})
+	return err
 }
 
+// decCommentsCount decreases comments_count of every discussion in removed
+// by the number of its comments that were deleted.
+func (s *ForumStorage) decCommentsCount(ctx context.Context, removed map[string]int) error {
+	var writes []mongo.WriteModel
+	for discussionID, count := range removed {
+		oid, err := primitive.ObjectIDFromHex(discussionID)
+		if err != nil {
+			continue
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": oid}).
+			SetUpdate(bson.M{"$inc": bson.M{"comments_count": -count}}))
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	_, err := s.discussions.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
 
+// DeleteAllDiscussions deletes every discussion of the user as a cascade
+// of the sanction and hides the comments on them with it.
+func (s *ForumStorage) DeleteAllDiscussions(ctx context.Context, userID int, sanctionID int64) error {
+	filter := bson.M{"author_id": userID, "deleted": false}
+	ids, err := s.discussions.Distinct(ctx, "_id", filter)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	hexIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			hexIDs = append(hexIDs, oid.Hex())
+		}
+	}
+	_, err = s.discussions.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "deleted": false}, deletion(models.DeletedByBan, 0, sanctionID))
+	if err != nil {
+		return err
+	}
+	_, err = s.comments.UpdateMany(ctx, bson.M{"discussion_id": bson.M{"$in": hexIDs}, "deleted": false}, deletion(models.DeletedWithDiscussion, 0, sanctionID))
+	return err
+}
@@ -0,0 +1,203 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"gohelp/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sortHeld names the order of the held queue in its cursors.
+const sortHeld = "held"
+
+// holding tells how a new post is stored: held posts are hidden like
+// deleted ones, so nothing shows them until they are approved.
+func holding(screening *models.Screening) (bool, string) {
+	if screening == nil {
+		return false, ""
+	}
+	return true, models.HeldForReview
+}
+
+var heldFilter = bson.M{"deleted": true, "deleted_cause": models.HeldForReview}
+
+// RecentContent returns the content of every discussion and comment the
+// user posted since the time, whatever became of them.
+func (s *ForumStorage) RecentContent(ctx context.Context, authorID int, since time.Time) ([]string, error) {
+	filter := bson.M{"author_id": authorID, "created_at": bson.M{"$gte": since}}
+	opts := options.Find().SetProjection(bson.M{"content": 1})
+	var content []string
+	for _, coll := range []*mongo.Collection{s.discussions, s.comments} {
+		cursor, err := coll.Find(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		var posts []struct {
+			Content string `bson:"content"`
+		}
+		if err = cursor.All(ctx, &posts); err != nil {
+			return nil, err
+		}
+		for _, post := range posts {
+			content = append(content, post.Content)
+		}
+	}
+	return content, nil
+}
+
+func heldDiscussion(d models.Discussion) models.HeldPost {
+	return models.HeldPost{
+		Type:      models.PostDiscussion,
+		ID:        d.ID,
+		Title:     d.Title,
+		Content:   d.Content,
+		AuthorID:  d.AuthorID,
+		CreatedAt: d.CreatedAt,
+		Screening: d.Screening,
+	}
+}
+
+func heldComment(c models.Comment) models.HeldPost {
+	return models.HeldPost{
+		Type:         models.PostComment,
+		ID:           c.ID,
+		DiscussionID: c.DiscussionID,
+		Content:      c.Content,
+		AuthorID:     c.AuthorID,
+		CreatedAt:    c.CreatedAt,
+		Screening:    c.Screening,
+	}
+}
+
+// GetHeldPosts returns one page of the held discussions and comments
+// together, the oldest first.
+func (s *ForumStorage) GetHeldPosts(ctx context.Context, query models.HeldListQuery) ([]models.HeldPost, string, error) {
+	filter := heldFilter
+	if query.Cursor != "" {
+		_, oid, err := decodeCursor(query.Cursor, sortHeld)
+		if err != nil {
+			return nil, "", err
+		}
+		filter = bson.M{"$and": []bson.M{heldFilter, {"_id": bson.M{"$gt": oid}}}}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(query.Limit + 1))
+
+	var discussions []models.Discussion
+	cursor, err := s.discussions.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	if err = cursor.All(ctx, &discussions); err != nil {
+		return nil, "", err
+	}
+	var comments []models.Comment
+	cursor, err = s.comments.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, "", err
+	}
+
+	// Both lists are sorted by _id, and ids of the same length compare in
+	// hex as they do in bytes.
+	posts := make([]models.HeldPost, 0, len(discussions)+len(comments))
+	for i, j := 0, 0; i < len(discussions) || j < len(comments); {
+		if j == len(comments) || (i < len(discussions) && discussions[i].ID < comments[j].ID) {
+			posts = append(posts, heldDiscussion(discussions[i]))
+			i++
+		} else {
+			posts = append(posts, heldComment(comments[j]))
+			j++
+		}
+	}
+	if len(posts) <= query.Limit {
+		return posts, "", nil
+	}
+	posts = posts[:query.Limit]
+	return posts, encodeCursor(pageCursor{Sort: sortHeld, ID: posts[len(posts)-1].ID}), nil
+}
+
+func (s *ForumStorage) postCollection(postType string) *mongo.Collection {
+	if postType == models.PostComment {
+		return s.comments
+	}
+	return s.discussions
+}
+
+// GetHeldPost returns the post if it is still waiting for review.
+func (s *ForumStorage) GetHeldPost(ctx context.Context, postType, postID string) (*models.HeldPost, error) {
+	oid, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	filter := bson.M{"$and": []bson.M{heldFilter, {"_id": oid}}}
+	var post models.HeldPost
+	if postType == models.PostComment {
+		var comment models.Comment
+		err = s.comments.FindOne(ctx, filter).Decode(&comment)
+		post = heldComment(comment)
+	} else {
+		var discussion models.Discussion
+		err = s.discussions.FindOne(ctx, filter).Decode(&discussion)
+		post = heldDiscussion(discussion)
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// reviewHeld applies the decision to the post if it is still held. Only
+// one of concurrent reviews succeeds, the others get ErrNotFound.
+func (s *ForumStorage) reviewHeld(ctx context.Context, postType, postID string, update bson.M) error {
+	oid, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return ErrNotFound
+	}
+	filter := bson.M{"$and": []bson.M{heldFilter, {"_id": oid}}}
+	res, err := s.postCollection(postType).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ApproveHeld publishes the held post. An approved comment is counted in
+// its discussion from now on.
+func (s *ForumStorage) ApproveHeld(ctx context.Context, post models.HeldPost, moderatorID int, note string) error {
+	err := s.reviewHeld(ctx, post.Type, post.ID, bson.M{
+		"$set":   bson.M{"deleted": false, "reviewed_by": moderatorID, "review_note": note},
+		"$unset": bson.M{"deleted_cause": ""},
+	})
+	if err != nil || post.Type != models.PostComment {
+		return err
+	}
+	discussionOID, err := primitive.ObjectIDFromHex(post.DiscussionID)
+	if err != nil {
+		return errors.New("invalid discussionID")
+	}
+	_, err = s.discussions.UpdateOne(ctx, bson.M{"_id": discussionOID}, bson.M{
+		"$inc": bson.M{"comments_count": 1},
+		"$max": bson.M{"last_activity_at": time.Now()},
+	})
+	return err
+}
+
+// RejectHeld keeps the held post hidden for good.
+func (s *ForumStorage) RejectHeld(ctx context.Context, post models.HeldPost, moderatorID int, note string) error {
+	return s.reviewHeld(ctx, post.Type, post.ID, bson.M{
+		"$set": bson.M{"deleted_cause": models.RejectedInReview, "deleted_by": moderatorID, "reviewed_by": moderatorID, "review_note": note},
+	})
+}
@@ -0,0 +1,172 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"gohelp/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// reputationScope selects the content whose reputation is summed up:
+// votes on matching discussions and comments, and accepted answers of
+// matching discussions when the answer itself matches comments.
+type reputationScope struct {
+	discussions bson.M
+	comments    bson.M
+	accepted    bson.M
+}
+
+// votesByAuthor groups the votes on matched documents of coll by author.
+// Votes of authors on their own content are ignored.
+func votesByAuthor(ctx context.Context, coll *mongo.Collection, match bson.M, scores map[int]int) error {
+	othersVotes := func(field string) bson.M {
+		return bson.M{"$size": bson.M{"$filter": bson.M{
+			"input": bson.M{"$ifNull": bson.A{"$" + field, bson.A{}}},
+			"cond":  bson.M{"$ne": bson.A{"$$this", "$author_id"}},
+		}}}
+	}
+	cursor, err := coll.Aggregate(ctx, bson.A{
+		bson.M{"$match": match},
+		bson.M{"$group": bson.M{
+			"_id": "$author_id",
+			"score": bson.M{"$sum": bson.M{"$add": bson.A{
+				bson.M{"$multiply": bson.A{othersVotes("likes"), models.ReputationLike}},
+				bson.M{"$multiply": bson.A{othersVotes("dislikes"), models.ReputationDislike}},
+			}}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	return sumScores(ctx, cursor, scores)
+}
+
+func sumScores(ctx context.Context, cursor *mongo.Cursor, scores map[int]int) error {
+	defer cursor.Close(ctx)
+	var rows []struct {
+		AuthorID int `bson:"_id"`
+		Score    int `bson:"score"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		scores[row.AuthorID] += row.Score
+	}
+	return nil
+}
+
+func (s *ForumStorage) reputationScores(ctx context.Context, scope reputationScope) (map[int]int, error) {
+	scores := make(map[int]int)
+	if scope.discussions != nil {
+		if err := votesByAuthor(ctx, s.discussions, scope.discussions, scores); err != nil {
+			return nil, err
+		}
+	}
+	if scope.comments != nil {
+		if err := votesByAuthor(ctx, s.comments, scope.comments, scores); err != nil {
+			return nil, err
+		}
+	}
+	if scope.accepted == nil {
+		return scores, nil
+	}
+
+	accepted := bson.M{"$and": []bson.M{
+		scope.accepted,
+		{"deleted": false, "resolved": true},
+	}}
+	cursor, err := s.discussions.Aggregate(ctx, bson.A{
+		bson.M{"$match": accepted},
+		bson.M{"$lookup": bson.M{
+			"from": "comments",
+			"let": bson.M{
+				"answer": bson.M{"$toObjectId": "$accepted_answer_id"},
+				"author": "$author_id",
+			},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$_id", "$$answer"}},
+					bson.M{"$ne": bson.A{"$author_id", "$$author"}},
+				}}}},
+				bson.M{"$match": scope.comments},
+			},
+			"as": "answer",
+		}},
+		bson.M{"$unwind": "$answer"},
+		bson.M{"$group": bson.M{
+			"_id":   "$answer.author_id",
+			"score": bson.M{"$sum": models.ReputationAccepted},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = sumScores(ctx, cursor, scores); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// ReputationOfAll returns the reputation every author gets from all the
+// content that is not deleted.
+func (s *ForumStorage) ReputationOfAll(ctx context.Context) (map[int]int, error) {
+	return s.reputationScores(ctx, reputationScope{
+		discussions: bson.M{"deleted": false},
+		comments:    bson.M{"deleted": false},
+		accepted:    bson.M{},
+	})
+}
+
+// ReputationOfDiscussion returns the reputation given by the discussion and
+// all of its comments.
+func (s *ForumStorage) ReputationOfDiscussion(ctx context.Context, discussionID string) (map[int]int, error) {
+	oid, err := primitive.ObjectIDFromHex(discussionID)
+	if err != nil {
+		return nil, errors.New("invalid discussionID")
+	}
+	return s.reputationScores(ctx, reputationScope{
+		discussions: bson.M{"_id": oid, "deleted": false},
+		comments:    bson.M{"discussion_id": discussionID, "deleted": false},
+		accepted:    bson.M{"_id": oid},
+	})
+}
+
+// ReputationOfComment returns the reputation given by the comment.
+func (s *ForumStorage) ReputationOfComment(ctx context.Context, commentID string) (map[int]int, error) {
+	oid, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, errors.New("invalid commentID")
+	}
+	return s.reputationScores(ctx, reputationScope{
+		comments: bson.M{"_id": oid, "deleted": false},
+		accepted: bson.M{"accepted_answer_id": commentID},
+	})
+}
+
+// ReputationOfAuthorContent returns the reputation given by everything
+// WipeUserContent removes for the user: their discussions with all
+// comments on them and their comments elsewhere.
+func (s *ForumStorage) ReputationOfAuthorContent(ctx context.Context, userID int) (map[int]int, error) {
+	ids, err := s.discussions.Distinct(ctx, "_id", bson.M{"author_id": userID, "deleted": false})
+	if err != nil {
+		return nil, err
+	}
+	hexIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			hexIDs = append(hexIDs, oid.Hex())
+		}
+	}
+	return s.reputationScores(ctx, reputationScope{
+		discussions: bson.M{"author_id": userID, "deleted": false},
+		comments: bson.M{"deleted": false, "$or": []bson.M{
+			{"author_id": userID},
+			{"discussion_id": bson.M{"$in": hexIDs}},
+		}},
+		accepted: bson.M{},
+	})
+}
@@ -0,0 +1,102 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"gohelp/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// editPost sets the new content of the post in coll and saves the content
+// it replaced as the next revision. The revision number is taken from the
+// counter on the post, which is bumped by the same update. An edit the
+// screening flagged is held by the same update too, so its content is
+// never shown, and a held comment stops counting in its discussion.
+func (s *ForumStorage) editPost(ctx context.Context, coll *mongo.Collection, postType, postID, content string, editorID int, screening *models.Screening) error {
+	oid, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return ErrNotFound
+	}
+	set := bson.M{"content": content, "edited": true}
+	if screening != nil {
+		set["deleted"], set["deleted_cause"] = holding(screening)
+		set["screening"] = screening
+	}
+	var previous struct {
+		Content      string `bson:"content"`
+		Revisions    int    `bson:"revisions"`
+		DiscussionID string `bson:"discussion_id"`
+	}
+	err = coll.FindOneAndUpdate(ctx,
+		bson.M{"_id": oid, "deleted": false},
+		bson.M{
+			"$set": set,
+			"$inc": bson.M{"revisions": 1},
+		},
+		options.FindOneAndUpdate().
+			SetReturnDocument(options.Before).
+			SetProjection(bson.M{"content": 1, "revisions": 1, "discussion_id": 1}),
+	).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.revisions.InsertOne(ctx, models.Revision{
+		PostID:   postID,
+		PostType: postType,
+		Version:  previous.Revisions + 1,
+		Content:  previous.Content,
+		EditorID: editorID,
+		EditedAt: time.Now(),
+	})
+	if err != nil || screening == nil || postType != models.PostComment {
+		return err
+	}
+	discussionOID, err := primitive.ObjectIDFromHex(previous.DiscussionID)
+	if err != nil {
+		return errors.New("invalid discussionID")
+	}
+	_, err = s.discussions.UpdateOne(ctx, bson.M{"_id": discussionOID}, bson.M{"$inc": bson.M{"comments_count": -1}})
+	return err
+}
+
+// GetRevisions returns all saved revisions of the post, oldest first.
+func (s *ForumStorage) GetRevisions(ctx context.Context, postType, postID string) ([]models.Revision, error) {
+	cursor, err := s.revisions.Find(ctx,
+		bson.M{"post_type": postType, "post_id": postID},
+		options.Find().SetSort(bson.M{"version": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	revisions := []models.Revision{}
+	if err = cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (s *ForumStorage) GetRevision(ctx context.Context, postType, postID string, version int) (*models.Revision, error) {
+	var revision models.Revision
+	err := s.revisions.FindOne(ctx, bson.M{
+		"post_type": postType,
+		"post_id":   postID,
+		"version":   version,
+	}).Decode(&revision)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -0,0 +1,131 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"gohelp/internal/models"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrTagExists = errors.New("tag already exists")
+
+func (s *ForumStorage) CreateTag(ctx context.Context, tag models.Tag) error {
+	_, err := s.tags.InsertOne(ctx, tag)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrTagExists
+	}
+	return err
+}
+
+func (s *ForumStorage) GetTag(ctx context.Context, name string) (*models.Tag, error) {
+	var tag models.Tag
+	err := s.tags.FindOne(ctx, bson.M{"_id": name}).Decode(&tag)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// CountExistingTags returns how many of names are in the tag catalog.
+func (s *ForumStorage) CountExistingTags(ctx context.Context, names []string) (int64, error) {
+	return s.tags.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": names}})
+}
+
+// GetTags returns the whole catalog with the number of non-deleted
+// discussions using every tag, most used first.
+func (s *ForumStorage) GetTags(ctx context.Context) ([]models.Tag, error) {
+	cursor, err := s.tags.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	tags := []models.Tag{}
+	if err = cursor.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+
+	usage, err := s.discussions.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"deleted": false}},
+		bson.M{"$unwind": "$tags"},
+		bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer usage.Close(ctx)
+	var counts []struct {
+		Name  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err = usage.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	byName := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		byName[c.Name] = c.Count
+	}
+	for i := range tags {
+		tags[i].UsageCount = byName[tags[i].Name]
+	}
+	// Tags come sorted by name, so equally used tags stay in that order.
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].UsageCount > tags[j].UsageCount })
+	return tags, nil
+}
+
+func (s *ForumStorage) DescribeTag(ctx context.Context, name, description string) error {
+	res, err := s.tags.UpdateOne(ctx, bson.M{"_id": name}, bson.M{
+		"$set": bson.M{"description": description},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *ForumStorage) DeleteTag(ctx context.Context, name string) error {
+	_, err := s.tags.DeleteOne(ctx, bson.M{"_id": name})
+	return err
+}
+
+// ReplaceTag replaces the tag from with the tag to on every discussion. A
+// discussion that already carries both keeps a single copy of to.
+func (s *ForumStorage) ReplaceTag(ctx context.Context, from, to string) error {
+	update := bson.A{
+		bson.M{"$set": bson.M{
+			"tags": bson.M{"$concatArrays": bson.A{
+				bson.M{"$filter": bson.M{
+					"input": "$tags",
+					"cond": bson.M{"$and": bson.A{
+						bson.M{"$ne": bson.A{"$$this", from}},
+						bson.M{"$ne": bson.A{"$$this", to}},
+					}},
+				}},
+				bson.A{to},
+			}},
+		}},
+	}
+	_, err := s.discussions.UpdateMany(ctx, bson.M{"tags": from}, update)
+	return err
+}
+
+func (s *ForumStorage) SetDiscussionTags(ctx context.Context, discussionID string, tags []string) error {
+	oid, err := primitive.ObjectIDFromHex(discussionID)
+	if err != nil {
+		return errors.New("invalid discussionID")
+	}
+	_, err = s.discussions.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$set": bson.M{"tags": tags},
+	})
+	return err
+}
@@ -0,0 +1,79 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gohelp/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ErrIdentityExists is returned when the provider account is already
+// linked to a user or the user already has an account of the provider.
+var ErrIdentityExists = errors.New("identity already linked")
+
+func (r *UserRepository) GetIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.GetContext(ctx, &identity, "SELECT id, user_id, provider, subject, email, created_at FROM user_identities WHERE provider = $1 AND subject = $2", provider, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *UserRepository) GetIdentities(ctx context.Context, userID int) ([]models.UserIdentity, error) {
+	identities := []models.UserIdentity{}
+	err := r.db.SelectContext(ctx, &identities, "SELECT id, user_id, provider, subject, email, created_at FROM user_identities WHERE user_id = $1 ORDER BY created_at", userID)
+	return identities, err
+}
+
+func (r *UserRepository) CreateIdentity(ctx context.Context, identity models.UserIdentity) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO user_identities (user_id, provider, subject, email) VALUES ($1, $2, $3, $4)",
+		identity.UserID, identity.Provider, identity.Subject, identity.Email)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return ErrIdentityExists
+	}
+	return err
+}
+
+// CreateUserWithIdentity creates the user and links the identity to them
+// in one transaction and returns the id of the new user.
+func (r *UserRepository) CreateUserWithIdentity(ctx context.Context, user models.User, identity models.UserIdentity) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var userID int
+	err = tx.QueryRowContext(ctx, "INSERT INTO users (username, email, password_hash, avatar_url, email_verified) VALUES ($1, $2, NULLIF($3, ''), $4, $5) RETURNING id",
+		user.Username, user.Email, user.PasswordHash, user.AvatarURL, user.EmailVerified).Scan(&userID)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, "INSERT INTO user_identities (user_id, provider, subject, email) VALUES ($1, $2, $3, $4)",
+			userID, identity.Provider, identity.Subject, identity.Email)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return 0, ErrUserExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return userID, tx.Commit()
+}
+
+// DeleteIdentity unlinks the provider from the user. It returns
+// sql.ErrNoRows if nothing was linked.
+func (r *UserRepository) DeleteIdentity(ctx context.Context, userID int, provider string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM user_identities WHERE user_id = $1 AND provider = $2", userID, provider)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
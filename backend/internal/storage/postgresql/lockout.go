@@ -0,0 +1,42 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// LoginLockedUntil returns when the user can try to log in again, zero if
+// the user is not locked out.
+func (r *UserRepository) LoginLockedUntil(ctx context.Context, userID int) (time.Time, error) {
+	var until sql.NullTime
+	err := r.db.QueryRowContext(ctx, "SELECT login_locked_until FROM users WHERE id = $1 AND login_locked_until > now()", userID).Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return until.Time, err
+}
+
+// RecordFailedLogin counts a failed login of the user and returns the
+// failures in a row. The count starts over when the last failure is older
+// than forgetAfter.
+func (r *UserRepository) RecordFailedLogin(ctx context.Context, userID int, forgetAfter time.Duration) (int, error) {
+	var failures int
+	err := r.db.QueryRowContext(ctx, `UPDATE users SET
+			failed_logins = CASE WHEN last_failed_login > now() - make_interval(secs => $2) THEN failed_logins + 1 ELSE 1 END,
+			last_failed_login = now()
+		WHERE id = $1 RETURNING failed_logins`, userID, forgetAfter.Seconds()).Scan(&failures)
+	return failures, err
+}
+
+func (r *UserRepository) LockLogin(ctx context.Context, userID int, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET login_locked_until = $1 WHERE id = $2", until, userID)
+	return err
+}
+
+// ResetFailedLogins forgets the failures of the user and lifts the lock.
+func (r *UserRepository) ResetFailedLogins(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET failed_logins = 0, last_failed_login = NULL, login_locked_until = NULL WHERE id = $1", userID)
+	return err
+}
@@ -0,0 +1,141 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gohelp/internal/models"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+const notificationColumns = "id, user_id, type, actor_id, discussion_id, comment_id, detail, created_at, read_at"
+
+// CreateNotifications stores the notifications whose receivers did not
+// switch their type off, and returns the stored ones.
+func (r *NotificationRepository) CreateNotifications(ctx context.Context, notifications []models.Notification) ([]models.Notification, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	created := make([]models.Notification, 0, len(notifications))
+	for _, n := range notifications {
+		var stored models.Notification
+		err = tx.GetContext(ctx, &stored, `INSERT INTO notifications (user_id, type, actor_id, discussion_id, comment_id, detail)
+			SELECT $1, $2, $3, $4, $5, $6
+			WHERE NOT EXISTS (SELECT 1 FROM notification_preferences WHERE user_id = $1 AND type = $2 AND NOT enabled)
+			ON CONFLICT (user_id, actor_id, discussion_id, comment_id) WHERE type = 'vote' AND read_at IS NULL
+			DO UPDATE SET detail = EXCLUDED.detail, created_at = now()
+			RETURNING `+notificationColumns,
+			n.UserID, n.Type, n.ActorID, n.DiscussionID, n.CommentID, n.Detail)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, stored)
+	}
+	return created, tx.Commit()
+}
+
+// GetNotifications returns one page of notifications of the user, the
+// latest first. The cursor of the next page is empty on the last page.
+func (r *NotificationRepository) GetNotifications(ctx context.Context, query models.NotificationListQuery) ([]models.Notification, string, error) {
+	before := int64(1 << 62)
+	if query.Cursor != "" {
+		var err error
+		if before, err = strconv.ParseInt(query.Cursor, 10, 64); err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+	}
+	notifications := []models.Notification{}
+	err := r.db.SelectContext(ctx, &notifications, "SELECT "+notificationColumns+` FROM notifications
+		WHERE user_id = $1 AND id < $2 AND (NOT $3 OR read_at IS NULL) ORDER BY id DESC LIMIT $4`,
+		query.UserID, before, query.UnreadOnly, query.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(notifications) <= query.Limit {
+		return notifications, "", nil
+	}
+	notifications = notifications[:query.Limit]
+	return notifications, strconv.FormatInt(notifications[len(notifications)-1].ID, 10), nil
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT count(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL", userID)
+	return count, err
+}
+
+// MarkRead marks the notification of the user as read. It returns
+// sql.ErrNoRows if the user has no such notification.
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID int, notificationID int64) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE notifications SET read_at = COALESCE(read_at, now()) WHERE id = $1 AND user_id = $2", notificationID, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkAllRead marks every notification of the user as read and returns
+// how many were unread.
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL", userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetPreferences returns the types the user set. Types missing from the
+// result are enabled.
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID int) (models.NotificationPreferences, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT type, enabled FROM notification_preferences WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	preferences := models.NotificationPreferences{}
+	for rows.Next() {
+		var notificationType string
+		var enabled bool
+		if err := rows.Scan(&notificationType, &enabled); err != nil {
+			return nil, err
+		}
+		preferences[notificationType] = enabled
+	}
+	return preferences, rows.Err()
+}
+
+func (r *NotificationRepository) SetPreferences(ctx context.Context, userID int, preferences models.NotificationPreferences) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for notificationType, enabled := range preferences {
+		_, err = tx.ExecContext(ctx, `INSERT INTO notification_preferences (user_id, type, enabled) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled`, userID, notificationType, enabled)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
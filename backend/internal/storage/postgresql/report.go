@@ -0,0 +1,214 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"gohelp/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrAlreadyReported is returned when the user flagged the open report of
+// the target before.
+var ErrAlreadyReported = errors.New("already reported")
+
+// ErrReportClosed is returned when the report was resolved in the
+// meantime.
+var ErrReportClosed = errors.New("report is already resolved")
+
+// ErrInvalidCursor is returned for a cursor that was not issued for the
+// listing.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type ReportRepository struct {
+	db *sqlx.DB
+}
+
+func NewReportRepository(db *sqlx.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+const reportColumns = "id, target_type, target_id, author_id, severity, reports_count, status, created_at, updated_at, resolved_at"
+
+// AddFlag flags the target for the reporter. The flag joins the open
+// report of the target, or opens one.
+func (r *ReportRepository) AddFlag(ctx context.Context, report models.Report, flag models.ReportFlag) (*models.Report, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	var reportID int64
+	err = tx.QueryRowContext(ctx, `INSERT INTO reports (target_type, target_id, author_id) VALUES ($1, $2, $3)
+		ON CONFLICT (target_type, target_id) WHERE status IN ('open', 'resolving') DO UPDATE SET updated_at = now()
+		RETURNING id`, report.TargetType, report.TargetID, report.AuthorID).Scan(&reportID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.ExecContext(ctx, "INSERT INTO report_flags (report_id, reporter_id, category, text) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING",
+		reportID, flag.ReporterID, flag.Category, flag.Text)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrAlreadyReported
+	}
+	var updated models.Report
+	err = tx.GetContext(ctx, &updated, "UPDATE reports SET reports_count = reports_count + 1, severity = GREATEST(severity, $2), updated_at = now() WHERE id = $1 RETURNING "+reportColumns,
+		reportID, models.ReportSeverity[flag.Category])
+	if err != nil {
+		return nil, err
+	}
+	return &updated, tx.Commit()
+}
+
+// GetReport returns the report with its flags and resolutions.
+func (r *ReportRepository) GetReport(ctx context.Context, reportID int64) (*models.Report, error) {
+	var report models.Report
+	err := r.db.GetContext(ctx, &report, "SELECT "+reportColumns+" FROM reports WHERE id = $1", reportID)
+	if err != nil {
+		return nil, err
+	}
+	report.Flags = []models.ReportFlag{}
+	err = r.db.SelectContext(ctx, &report.Flags, "SELECT reporter_id, category, text, created_at FROM report_flags WHERE report_id = $1 ORDER BY created_at", reportID)
+	if err != nil {
+		return nil, err
+	}
+	report.Resolutions = []models.ReportResolution{}
+	err = r.db.SelectContext(ctx, &report.Resolutions, "SELECT id, report_id, moderator_id, action, note, sanction_id, created_at FROM report_resolutions WHERE report_id = $1 ORDER BY created_at", reportID)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// reportCursor is the position of the last report of a page.
+type reportCursor struct {
+	Status   string `json:"s"`
+	ID       int64  `json:"id"`
+	Severity int    `json:"sv,omitempty"`
+	Count    int    `json:"c,omitempty"`
+}
+
+func encodeReportCursor(c reportCursor) string {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeReportCursor(s, status string) (*reportCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c reportCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Status != status {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// GetReports returns one page of reports. Open reports come as the
+// moderation queue, the most severe and most reported first, resolved ones
+// the latest first. The cursor of the next page is empty on the last page.
+func (r *ReportRepository) GetReports(ctx context.Context, query models.ReportListQuery) ([]models.Report, string, error) {
+	var after *reportCursor
+	if query.Cursor != "" {
+		var err error
+		if after, err = decodeReportCursor(query.Cursor, query.Status); err != nil {
+			return nil, "", err
+		}
+	}
+	reports := []models.Report{}
+	var err error
+	if query.Status == models.ReportOpen {
+		if after == nil {
+			after = &reportCursor{Severity: 1 << 30}
+		}
+		err = r.db.SelectContext(ctx, &reports, "SELECT "+reportColumns+` FROM reports WHERE status = 'open'
+			AND (severity < $1 OR (severity = $1 AND reports_count < $2) OR (severity = $1 AND reports_count = $2 AND id > $3))
+			ORDER BY severity DESC, reports_count DESC, id LIMIT $4`,
+			after.Severity, after.Count, after.ID, query.Limit+1)
+	} else {
+		if after == nil {
+			after = &reportCursor{ID: 1 << 62}
+		}
+		err = r.db.SelectContext(ctx, &reports, "SELECT "+reportColumns+" FROM reports WHERE status = $1 AND id < $2 ORDER BY id DESC LIMIT $3",
+			query.Status, after.ID, query.Limit+1)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if len(reports) <= query.Limit {
+		return reports, "", nil
+	}
+	reports = reports[:query.Limit]
+	last := reports[len(reports)-1]
+	return reports, encodeReportCursor(reportCursor{
+		Status:   query.Status,
+		ID:       last.ID,
+		Severity: last.Severity,
+		Count:    last.ReportsCount,
+	}), nil
+}
+
+// ResolveReport closes the open report and records what the moderator
+// did. Only one of concurrent resolutions succeeds, the others get
+// ErrReportClosed.
+// ClaimReport moves the open report to resolving, so that only one
+// moderator acts on it. A claim older than staleAfter is taken over, its
+// moderator is assumed gone. It returns ErrReportClosed if the report is
+// resolved or claimed.
+func (r *ReportRepository) ClaimReport(ctx context.Context, reportID int64, staleAfter time.Duration) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE reports SET status = 'resolving', updated_at = now()
+		WHERE id = $1 AND (status = 'open' OR (status = 'resolving' AND updated_at < now() - make_interval(secs => $2)))`,
+		reportID, staleAfter.Seconds())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrReportClosed
+	}
+	return nil
+}
+
+// ReleaseReport opens the claimed report again.
+func (r *ReportRepository) ReleaseReport(ctx context.Context, reportID int64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE reports SET status = 'open', updated_at = now() WHERE id = $1 AND status = 'resolving'", reportID)
+	return err
+}
+
+// ResolveReport closes the claimed report and records the resolution.
+func (r *ReportRepository) ResolveReport(ctx context.Context, resolution models.ReportResolution) (*models.ReportResolution, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, "UPDATE reports SET status = 'resolved', resolved_at = now(), updated_at = now() WHERE id = $1 AND status = 'resolving'", resolution.ReportID)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrReportClosed
+	}
+	var created models.ReportResolution
+	err = tx.GetContext(ctx, &created, `INSERT INTO report_resolutions (report_id, moderator_id, action, note, sanction_id) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, report_id, moderator_id, action, note, sanction_id, created_at`,
+		resolution.ReportID, resolution.ModeratorID, resolution.Action, resolution.Note, resolution.SanctionID)
+	if err != nil {
+		return nil, err
+	}
+	return &created, tx.Commit()
+}
@@ -0,0 +1,70 @@
+package postgresql
+
+import (
+	"context"
+	"gohelp/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// activeSanction matches sanctions that keep the user out right now. An
+// expired sanction stops matching by itself, nothing has to lift it.
+const activeSanction = "lifted_at IS NULL AND starts_at <= now() AND (expires_at IS NULL OR expires_at > now())"
+
+// bannedColumn selects if the user in the users row is suspended.
+const bannedColumn = "EXISTS (SELECT 1 FROM user_sanctions WHERE user_sanctions.user_id = users.id AND " + activeSanction + ")"
+
+const sanctionColumns = "id, user_id, reason, issued_by, starts_at, expires_at, content_wiped, content_restored, lifted_at, lifted_by, lift_reason"
+
+func (r *UserRepository) CreateSanction(ctx context.Context, sanction models.Sanction) (*models.Sanction, error) {
+	var created models.Sanction
+	err := r.db.GetContext(ctx, &created, "INSERT INTO user_sanctions (user_id, reason, issued_by, expires_at, content_wiped) VALUES ($1, $2, $3, $4, $5) RETURNING "+sanctionColumns,
+		sanction.UserID, sanction.Reason, sanction.IssuedBy, sanction.ExpiresAt, sanction.ContentWiped)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetSanctions returns the whole sanction history of the user, the latest
+// first.
+func (r *UserRepository) GetSanctions(ctx context.Context, userID int) ([]models.Sanction, error) {
+	sanctions := []models.Sanction{}
+	err := r.db.SelectContext(ctx, &sanctions, "SELECT "+sanctionColumns+" FROM user_sanctions WHERE user_id = $1 ORDER BY starts_at DESC, id DESC", userID)
+	return sanctions, err
+}
+
+// GetActiveSanction returns the active sanction of the user that ends the
+// last. It returns sql.ErrNoRows if the user is not suspended.
+func (r *UserRepository) GetActiveSanction(ctx context.Context, userID int) (*models.Sanction, error) {
+	var sanction models.Sanction
+	err := r.db.GetContext(ctx, &sanction, "SELECT "+sanctionColumns+" FROM user_sanctions WHERE user_id = $1 AND "+activeSanction+" ORDER BY expires_at DESC NULLS FIRST LIMIT 1", userID)
+	if err != nil {
+		return nil, err
+	}
+	return &sanction, nil
+}
+
+// LiftSanctions lifts every active sanction of the user and returns how
+// many there were.
+func (r *UserRepository) LiftSanctions(ctx context.Context, userID, liftedBy int, reason string) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "UPDATE user_sanctions SET lifted_at = now(), lifted_by = $2, lift_reason = $3 WHERE user_id = $1 AND "+activeSanction,
+		userID, liftedBy, reason)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetWipedSanctions returns the ids of sanctions of the user that are not
+// active anymore and whose wiped content was not restored yet.
+func (r *UserRepository) GetWipedSanctions(ctx context.Context, userID int) ([]int64, error) {
+	var ids []int64
+	err := r.db.SelectContext(ctx, &ids, "SELECT id FROM user_sanctions WHERE user_id = $1 AND content_wiped AND NOT content_restored AND NOT ("+activeSanction+")", userID)
+	return ids, err
+}
+
+func (r *UserRepository) SetContentRestored(ctx context.Context, sanctionIDs []int64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE user_sanctions SET content_restored = TRUE WHERE id = ANY($1)", pq.Array(sanctionIDs))
+	return err
+}
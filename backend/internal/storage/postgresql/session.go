@@ -0,0 +1,110 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"gohelp/internal/models"
+)
+
+// ErrTokenRevoked is returned when a refresh token was already used or
+// revoked in the meantime.
+var ErrTokenRevoked = errors.New("refresh token revoked")
+
+func (r *UserRepository) CreateRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO refresh_tokens (user_id, session_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)",
+		token.UserID, token.SessionID, token.TokenHash, token.ExpiresAt)
+	return err
+}
+
+func (r *UserRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.GetContext(ctx, &token, "SELECT id, user_id, session_id, token_hash, created_at, expires_at, revoked_at FROM refresh_tokens WHERE token_hash = $1", tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken revokes the token with oldID and stores next in its
+// place. Only one of concurrent rotations of the same token succeeds, the
+// others get ErrTokenRevoked.
+func (r *UserRepository) RotateRefreshToken(ctx context.Context, oldID int64, next models.RefreshToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL", oldID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrTokenRevoked
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO refresh_tokens (user_id, session_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)",
+		next.UserID, next.SessionID, next.TokenHash, next.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RevokeSession revokes every token of the session.
+func (r *UserRepository) RevokeSession(ctx context.Context, sessionID string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = now() WHERE session_id = $1 AND revoked_at IS NULL", sessionID)
+	return err
+}
+
+// RevokeAllSessions revokes every refresh token of the user and bumps their
+// token version, which makes access tokens issued so far invalid.
+func (r *UserRepository) RevokeAllSessions(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, "UPDATE users SET token_version = token_version + 1 WHERE id = $1", userID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL", userID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *UserRepository) CreateUserToken(ctx context.Context, token models.UserToken) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)",
+		token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt)
+	return err
+}
+
+// ConsumeUserToken marks the token as used and returns its user. A token
+// that is unknown, expired or already used gives sql.ErrNoRows.
+func (r *UserRepository) ConsumeUserToken(ctx context.Context, purpose, tokenHash string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `UPDATE user_tokens SET used_at = now()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > now()
+		RETURNING user_id`, tokenHash, purpose).Scan(&userID)
+	return userID, err
+}
+
+// DiscardUserTokens makes every unused token of the user with the purpose
+// unusable.
+func (r *UserRepository) DiscardUserTokens(ctx context.Context, userID int, purpose string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE user_tokens SET used_at = now() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL", userID, purpose)
+	return err
+}
+
+func (r *UserRepository) SetEmailVerified(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET email_verified = TRUE WHERE id = $1", userID)
+	return err
+}
+
+func (r *UserRepository) SetPassword(ctx context.Context, userID int, passwordHash string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET password_hash = $1 WHERE id = $2", passwordHash, userID)
+	return err
+}
@@ -0,0 +1,99 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"gohelp/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type SubscriptionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSubscriptionRepository(db *sqlx.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db}
+}
+
+// Subscribe makes the user follow the target. Following it again changes
+// nothing.
+func (r *SubscriptionRepository) Subscribe(ctx context.Context, userID int, target models.SubscriptionTarget) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO subscriptions (user_id, target_type, target_id) VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`, userID, target.Type, target.ID)
+	return err
+}
+
+// Unsubscribe stops the user following the target. It returns
+// sql.ErrNoRows if the user did not follow it.
+func (r *SubscriptionRepository) Unsubscribe(ctx context.Context, userID int, target models.SubscriptionTarget) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM subscriptions WHERE user_id = $1 AND target_type = $2 AND target_id = $3",
+		userID, target.Type, target.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetSubscriptions returns everything the user follows, the latest first.
+func (r *SubscriptionRepository) GetSubscriptions(ctx context.Context, userID int) ([]models.Subscription, error) {
+	subscriptions := []models.Subscription{}
+	err := r.db.SelectContext(ctx, &subscriptions, `SELECT user_id, target_type, target_id, created_at FROM subscriptions
+		WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	return subscriptions, err
+}
+
+// GetSubscribers returns who follows any of the targets, with the first of
+// the targets in the given order each of them follows.
+func (r *SubscriptionRepository) GetSubscribers(ctx context.Context, targets []models.SubscriptionTarget) (map[int]models.SubscriptionTarget, error) {
+	types := make([]string, len(targets))
+	ids := make([]string, len(targets))
+	for i, target := range targets {
+		types[i], ids[i] = target.Type, target.ID
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT ON (s.user_id) s.user_id, s.target_type, s.target_id
+		FROM subscriptions s JOIN unnest($1::text[], $2::text[]) WITH ORDINALITY AS t (target_type, target_id, position)
+			ON s.target_type = t.target_type AND s.target_id = t.target_id
+		ORDER BY s.user_id, t.position`, pq.Array(types), pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	subscribers := map[int]models.SubscriptionTarget{}
+	for rows.Next() {
+		var userID int
+		var target models.SubscriptionTarget
+		if err := rows.Scan(&userID, &target.Type, &target.ID); err != nil {
+			return nil, err
+		}
+		subscribers[userID] = target
+	}
+	return subscribers, rows.Err()
+}
+
+// MoveSubscriptions makes the followers of one target follow another one
+// instead, as when a tag is renamed or merged.
+func (r *SubscriptionRepository) MoveSubscriptions(ctx context.Context, from, to models.SubscriptionTarget) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, `INSERT INTO subscriptions (user_id, target_type, target_id, created_at)
+		SELECT user_id, $3, $4, created_at FROM subscriptions WHERE target_type = $1 AND target_id = $2
+		ON CONFLICT DO NOTHING`, from.Type, from.ID, to.Type, to.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM subscriptions WHERE target_type = $1 AND target_id = $2", from.Type, from.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -2,10 +2,12 @@ package postgresql
 
 import (
 	"context"
+	"errors"
 	"gohelp/internal/models"
-	"log"
+	"sort"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type UserRepository struct {
@@ -16,28 +18,144 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// ErrUserExists is returned when the username or email is already taken.
+var ErrUserExists = errors.New("user already exists")
+
+// uniqueViolation is the postgres error code of a unique constraint failure.
+const uniqueViolation = "23505"
+
 func (r *UserRepository) CreateUser(ctx context.Context, user models.User) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO users (username, email, password_hash, password) VALUES ($1, $2, $3, $4)",
-		user.Username, user.Email, user.PasswordHash, user.Password)
+	_, err := r.db.ExecContext(ctx, "INSERT INTO users (username, email, password_hash, avatar_url, email_verified) VALUES ($1, $2, NULLIF($3, ''), $4, $5)",
+		user.Username, user.Email, user.PasswordHash, user.AvatarURL, user.EmailVerified)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return ErrUserExists
+	}
 	return err
 }
 
 func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.QueryRowContext(ctx, "SELECT id, username, email, password_hash, user_role, banned FROM users WHERE email=$1", email).
-		Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.Banned)
+	err := r.db.QueryRowContext(ctx, "SELECT id, username, email, COALESCE(password_hash, ''), user_role, "+bannedColumn+", reputation, avatar_url, created_at, email_verified, token_version FROM users WHERE email=$1", email).
+		Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.Banned, &user.Reputation, &user.AvatarURL, &user.CreatedAt, &user.EmailVerified, &user.TokenVersion)
 	return &user, err
 }
 
 func (r *UserRepository) GetUserById(ctx context.Context, userID int) (*models.User, error) {
 	var user models.User
-	err := r.db.QueryRowContext(ctx, "SELECT id, username, email, password_hash, user_role, banned FROM users WHERE id=$1", userID).
-		Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.Banned)
+	err := r.db.QueryRowContext(ctx, "SELECT id, username, email, COALESCE(password_hash, ''), user_role, "+bannedColumn+", reputation, avatar_url, created_at, email_verified, token_version FROM users WHERE id=$1", userID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.Banned, &user.Reputation, &user.AvatarURL, &user.CreatedAt, &user.EmailVerified, &user.TokenVersion)
 	return &user, err
 }
 
-func (r *UserRepository) ChangeBanStatus(ctx context.Context, userID int, status bool) error {
-	log.Println(userID, status)
-	_, err := r.db.Exec("UPDATE users SET banned = $1 WHERE id = $2", status, userID)
+// GetAuthors loads the public view of all users in ids with one query.
+func (r *UserRepository) GetAuthors(ctx context.Context, ids []int) ([]models.Author, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, username, avatar_url, reputation FROM users WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var authors []models.Author
+	for rows.Next() {
+		var author models.Author
+		if err := rows.Scan(&author.ID, &author.Username, &author.AvatarURL, &author.Reputation); err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+	return authors, rows.Err()
+}
+
+// GetUserIDsByUsernames returns the ids of the users with the names,
+// compared case-insensitively, by the lower-cased name.
+func (r *UserRepository) GetUserIDsByUsernames(ctx context.Context, usernames []string) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, lower(username) FROM users WHERE lower(username) = ANY($1)", pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make(map[string]int, len(usernames))
+	for rows.Next() {
+		var id int
+		var username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		ids[username] = id
+	}
+	return ids, rows.Err()
+}
+
+func (r *UserRepository) SetRole(ctx context.Context, userID int, role string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET user_role = $1 WHERE id = $2", role, userID)
 	return err
 }
+
+// reputationRows returns the users of m sorted by id with their values, so
+// that their rows are always locked in the same order.
+func reputationRows(m map[int]int) ([]int64, []int64) {
+	ids := make([]int64, 0, len(m))
+	for userID := range m {
+		ids = append(ids, int64(userID))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	values := make([]int64, len(ids))
+	for i, userID := range ids {
+		values[i] = int64(m[int(userID)])
+	}
+	return ids, values
+}
+
+// AddReputation changes reputation of every user in deltas by the given
+// amount in one transaction. The rows are locked in the order of ids, so
+// concurrent changes wait for each other instead of deadlocking.
+func (r *UserRepository) AddReputation(ctx context.Context, deltas map[int]int) error {
+	changed := map[int]int{}
+	for userID, delta := range deltas {
+		if delta != 0 {
+			changed[userID] = delta
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	ids, values := reputationRows(changed)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, "SELECT id FROM users WHERE id = ANY($1::int[]) ORDER BY id FOR UPDATE", pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE users u SET reputation = u.reputation + t.delta
+		FROM unnest($1::int[], $2::int[]) AS t (id, delta) WHERE u.id = t.id`, pq.Array(ids), pq.Array(values))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ResetReputation sets reputation of users in scores to the given values
+// and of everyone else to zero. It locks every user in the order of ids
+// first, the same order AddReputation uses.
+func (r *UserRepository) ResetReputation(ctx context.Context, scores map[int]int) error {
+	ids, values := reputationRows(scores)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, "SELECT id FROM users ORDER BY id FOR UPDATE")
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE users u SET reputation = COALESCE(t.score, 0)
+		FROM users v LEFT JOIN unnest($1::int[], $2::int[]) AS t (id, score) ON t.id = v.id
+		WHERE u.id = v.id AND u.reputation IS DISTINCT FROM COALESCE(t.score, 0)`, pq.Array(ids), pq.Array(values))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,20 @@
+// Package migrations holds the schema changes applied by the migrate
+// subcommand of the server.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed postgres/*.sql
+var postgresFiles embed.FS
+
+// Postgres returns the SQL migration files.
+func Postgres() fs.FS {
+	files, err := fs.Sub(postgresFiles, "postgres")
+	if err != nil {
+		panic(err)
+	}
+	return files
+}
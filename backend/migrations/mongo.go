@@ -0,0 +1,148 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"gohelp/internal/models"
+	"gohelp/internal/storage/migrate"
+	forum "gohelp/internal/storage/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type collectionIndexes struct {
+	collection string
+	indexes    []mongo.IndexModel
+}
+
+// createIndexes creates the indexes and dropIndexes removes them by name,
+// so every index needs one.
+func createIndexes(all []collectionIndexes) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, c := range all {
+			if _, err := db.Collection(c.collection).Indexes().CreateMany(ctx, c.indexes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func dropIndexes(all []collectionIndexes) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, c := range all {
+			for _, index := range c.indexes {
+				_, err := db.Collection(c.collection).Indexes().DropOne(ctx, *index.Options.Name)
+				if err != nil && !isIndexNotFound(err) {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// isIndexNotFound tells if err is about a missing index (27) or a missing
+// collection (26), which leave nothing to drop.
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && (cmdErr.Code == 27 || cmdErr.Code == 26)
+}
+
+var forumIndexes = []collectionIndexes{
+	{"discussions", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "title", Value: "text"}}, Options: options.Index().SetName("title_text")},
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetName("author_id_created_at")},
+	}},
+	{"comments", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "discussion_id", Value: 1}}, Options: options.Index().SetName("discussion_id")},
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetName("author_id_created_at")},
+		{Keys: bson.D{{Key: "related_to", Value: 1}}, Options: options.Index().SetName("related_to")},
+	}},
+	{"revisions", []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "post_type", Value: 1}, {Key: "post_id", Value: 1}, {Key: "version", Value: 1}},
+			Options: options.Index().SetName("post_version").SetUnique(true),
+		},
+	}},
+}
+
+// heldIndexes serve the queue of posts held by the screening.
+var heldIndexes = []collectionIndexes{
+	{"discussions", []mongo.IndexModel{heldIndex()}},
+	{"comments", []mongo.IndexModel{heldIndex()}},
+}
+
+// heldIndex leads with deleted_cause, the _id index cannot be partial.
+func heldIndex() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: "deleted_cause", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetName("held_for_review").
+			SetPartialFilterExpression(bson.M{"deleted_cause": models.HeldForReview}),
+	}
+}
+
+// feedIndexes serve the feed of followed tags and discussions.
+var feedIndexes = []collectionIndexes{
+	{"discussions", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tags", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetName("tags_created_at")},
+	}},
+	{"comments", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "discussion_id", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetName("discussion_id_created_at")},
+	}},
+}
+
+// Mongo lists the changes of the forum database.
+var Mongo = []migrate.MongoMigration{
+	{
+		Version: 1,
+		Name:    "create_forum_indexes",
+		Up:      createIndexes(forumIndexes),
+		Down:    dropIndexes(forumIndexes),
+	},
+	{
+		Version: 2,
+		Name:    "backfill_discussion_counters",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return forum.NewForumStorage(db, db.Client()).BackfillDiscussionCounters(ctx)
+		},
+		// The counters are kept up to date from now on, there is nothing
+		// to undo.
+		Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+	},
+	{
+		Version: 3,
+		Name:    "tag_deletion_causes",
+		Up:      tagDeletionCauses,
+		// Older code ignores the causes.
+		Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+	},
+	{
+		Version: 4,
+		Name:    "index_held_posts",
+		Up:      createIndexes(heldIndexes),
+		Down:    dropIndexes(heldIndexes),
+	},
+	{
+		Version: 5,
+		Name:    "index_feed",
+		Up:      createIndexes(feedIndexes),
+		Down:    dropIndexes(feedIndexes),
+	},
+}
+
+// tagDeletionCauses marks content wiped by sanctions before deletions had
+// causes as a ban cascade, so that lifting the sanction can restore it.
+// Other content deleted back then has no cause and is never restored.
+func tagDeletionCauses(ctx context.Context, db *mongo.Database) error {
+	filter := bson.M{"deleted": true, "sanction_id": bson.M{"$exists": true}, "deleted_cause": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"deleted_cause": models.DeletedByBan}}
+	for _, collection := range []string{"discussions", "comments"} {
+		if _, err := db.Collection(collection).UpdateMany(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+-- The up migration adopts a users table that existed before migrations
+-- did, so it never drops one that still holds accounts. Empty it by hand
+-- first if that is really meant.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users) THEN
+        RAISE EXCEPTION 'users is not empty, refusing to drop it';
+    END IF;
+END
+$$;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS users (
+    id            SERIAL PRIMARY KEY,
+    username      VARCHAR(50)  NOT NULL UNIQUE,
+    email         VARCHAR(255) NOT NULL UNIQUE,
+    password_hash TEXT         NOT NULL,
+    password      TEXT,
+    user_role     VARCHAR(20)  NOT NULL DEFAULT 'customer',
+    banned        BOOLEAN      NOT NULL DEFAULT FALSE
+);
@@ -0,0 +1,5 @@
+UPDATE users SET password_hash = ' ' WHERE password_hash IS NULL;
+ALTER TABLE users ALTER COLUMN password_hash SET NOT NULL;
+
+-- The plain text passwords are gone for good, the column comes back empty.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password TEXT;
@@ -0,0 +1,5 @@
+ALTER TABLE users DROP COLUMN IF EXISTS password;
+
+-- Accounts without a password, e.g. created through OAuth, have no hash.
+ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
+UPDATE users SET password_hash = NULL WHERE btrim(password_hash) = '';
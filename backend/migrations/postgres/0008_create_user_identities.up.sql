@@ -0,0 +1,12 @@
+-- External accounts a user signs in with. A provider account is linked to
+-- at most one user and a user has at most one account of each provider.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id         BIGSERIAL    PRIMARY KEY,
+    user_id    INTEGER      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider   VARCHAR(50)  NOT NULL,
+    subject    VARCHAR(255) NOT NULL,
+    email      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
@@ -0,0 +1,5 @@
+-- Roles between customer and admin did not exist before, their users go
+-- back to customer.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_user_role_check;
+UPDATE users SET user_role = 'customer' WHERE user_role IN ('user', 'trusted', 'moderator');
+ALTER TABLE users ALTER COLUMN user_role SET DEFAULT 'customer';
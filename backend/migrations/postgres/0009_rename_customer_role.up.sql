@@ -0,0 +1,3 @@
+UPDATE users SET user_role = 'user' WHERE user_role = 'customer';
+ALTER TABLE users ALTER COLUMN user_role SET DEFAULT 'user';
+ALTER TABLE users ADD CONSTRAINT users_user_role_check CHECK (user_role IN ('user', 'trusted', 'moderator', 'admin'));
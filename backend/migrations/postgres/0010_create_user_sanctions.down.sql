@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE users SET banned = TRUE WHERE id IN (
+    SELECT user_id FROM user_sanctions
+    WHERE lifted_at IS NULL AND starts_at <= now() AND (expires_at IS NULL OR expires_at > now())
+);
+DROP TABLE IF EXISTS user_sanctions;
@@ -0,0 +1,25 @@
+-- A sanction keeps a user out from starts_at until expires_at, or for good
+-- when it has no expiry, unless it is lifted earlier. Content wiped with a
+-- sanction is tagged with its id in Mongo so that it can be restored.
+CREATE TABLE IF NOT EXISTS user_sanctions (
+    id               BIGSERIAL   PRIMARY KEY,
+    user_id          INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    reason           TEXT        NOT NULL,
+    issued_by        INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    starts_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at       TIMESTAMPTZ,
+    content_wiped    BOOLEAN     NOT NULL DEFAULT FALSE,
+    content_restored BOOLEAN     NOT NULL DEFAULT FALSE,
+    lifted_at        TIMESTAMPTZ,
+    lifted_by        INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    lift_reason      TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS user_sanctions_user_id_idx ON user_sanctions (user_id, starts_at DESC);
+
+-- Bans from before had their content wiped without a tag, so there is
+-- nothing to restore for them.
+INSERT INTO user_sanctions (user_id, reason, content_wiped, content_restored)
+SELECT id, 'banned before sanctions were recorded', TRUE, TRUE FROM users WHERE banned;
+
+ALTER TABLE users DROP COLUMN IF EXISTS banned;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS report_resolutions;
+DROP TABLE IF EXISTS report_flags;
+DROP TABLE IF EXISTS reports;
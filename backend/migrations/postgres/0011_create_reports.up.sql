@@ -0,0 +1,40 @@
+-- A report collects the flags of one discussion, comment or user while it
+-- is open. Once resolved, new flags open a new report.
+CREATE TABLE IF NOT EXISTS reports (
+    id            BIGSERIAL   PRIMARY KEY,
+    target_type   VARCHAR(20) NOT NULL,
+    target_id     VARCHAR(64) NOT NULL,
+    author_id     INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    severity      INTEGER     NOT NULL DEFAULT 0,
+    reports_count INTEGER     NOT NULL DEFAULT 0,
+    status        VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at   TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_target_idx ON reports (target_type, target_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS reports_queue_idx ON reports (severity DESC, reports_count DESC, id) WHERE status = 'open';
+
+-- A user flags a report once, so reports_count counts distinct reporters.
+CREATE TABLE IF NOT EXISTS report_flags (
+    report_id   BIGINT      NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    reporter_id INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    category    VARCHAR(20) NOT NULL,
+    text        TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (report_id, reporter_id)
+);
+
+-- The audit log of what moderators did with reports.
+CREATE TABLE IF NOT EXISTS report_resolutions (
+    id           BIGSERIAL   PRIMARY KEY,
+    report_id    BIGINT      NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    moderator_id INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    action       VARCHAR(20) NOT NULL,
+    note         TEXT        NOT NULL DEFAULT '',
+    sanction_id  BIGINT      REFERENCES user_sanctions (id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS report_resolutions_report_id_idx ON report_resolutions (report_id);
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS failed_logins INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_failed_login TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS login_locked_until TIMESTAMPTZ;
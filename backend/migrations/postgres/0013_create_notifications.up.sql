@@ -0,0 +1,28 @@
+-- Posts live in mongo, so discussion_id and comment_id are not foreign
+-- keys. comment_id is empty for notifications about a discussion.
+CREATE TABLE IF NOT EXISTS notifications (
+    id            BIGSERIAL   PRIMARY KEY,
+    user_id       INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type          VARCHAR(30) NOT NULL,
+    actor_id      INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    discussion_id VARCHAR(64) NOT NULL DEFAULT '',
+    comment_id    VARCHAR(64) NOT NULL DEFAULT '',
+    detail        VARCHAR(50) NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    read_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications (user_id, id DESC);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
+-- A voter changing the vote updates the unread notification instead of
+-- adding one more.
+CREATE UNIQUE INDEX IF NOT EXISTS notifications_unread_vote_idx ON notifications (user_id, actor_id, discussion_id, comment_id)
+    WHERE type = 'vote' AND read_at IS NULL;
+
+-- Types missing here are enabled.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type    VARCHAR(30) NOT NULL,
+    enabled BOOLEAN     NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
@@ -0,0 +1,12 @@
+-- Users follow discussions, tags and other users. Discussions and tags
+-- live in mongo, so target_id is not a foreign key, user ids are stored
+-- as text.
+CREATE TABLE IF NOT EXISTS subscriptions (
+    user_id     INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    target_type VARCHAR(20) NOT NULL,
+    target_id   VARCHAR(64) NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, target_type, target_id)
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_target_idx ON subscriptions (target_type, target_id);
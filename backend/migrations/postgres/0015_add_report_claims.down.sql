@@ -0,0 +1,3 @@
+UPDATE reports SET status = 'open' WHERE status = 'resolving';
+DROP INDEX IF EXISTS reports_open_target_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_target_idx ON reports (target_type, target_id) WHERE status = 'open';
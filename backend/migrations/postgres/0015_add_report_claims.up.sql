@@ -0,0 +1,4 @@
+-- A moderator claims a report by moving it to 'resolving' before acting on
+-- it. The claimed report still collects new flags of its target.
+DROP INDEX IF EXISTS reports_open_target_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_target_idx ON reports (target_type, target_id) WHERE status IN ('open', 'resolving');
@@ -0,0 +1,51 @@
+// Package mailer sends plain text emails. The implementation is chosen by
+// MAIL_DRIVER: "smtp" sends through a server, "file" appends every message
+// to MAIL_FILE and "log" (the default) writes them to the log.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+func FromEnv() (Mailer, error) {
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		})
+	case "file":
+		f, err := os.OpenFile(os.Getenv("MAIL_FILE"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("error during opening mail file: %v", err)
+		}
+		return NewWriterMailer(f), nil
+	case "", "log":
+		return NewWriterMailer(log.Writer()), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", driver)
+	}
+}
+
+// format renders msg the way it is written to files and sent over SMTP.
+func format(from string, msg Message) string {
+	return fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from, msg.To, msg.Subject, msg.Body)
+}
+
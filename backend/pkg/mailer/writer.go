@@ -0,0 +1,27 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// WriterMailer writes messages to w instead of sending them. It is meant
+// for development and tests.
+type WriterMailer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterMailer(w io.Writer) *WriterMailer {
+	return &WriterMailer{w: w}
+}
+
+func (m *WriterMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	text := strings.ReplaceAll(format("gohelp", msg), "\r\n", "\n")
+	_, err := io.WriteString(m.w, text+"\n")
+	return err
+}
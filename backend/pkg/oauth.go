@@ -1,35 +1,182 @@
 package pkg
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"sort"
 
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/github"
+	"github.com/markbates/goth/providers/gitlab"
 	"github.com/markbates/goth/providers/google"
+	"github.com/markbates/goth/providers/openidConnect"
 )
 
-func InitOAuth() {
-	goth.UseProviders(
-		google.New(os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"), "http://localhost:8080/auth/google/callback"),
-	)
+// Types of OAuth providers that can be configured.
+const (
+	ProviderGoogle = "google"
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	ProviderOIDC   = "oidc"
+)
+
+// OAuthProvider is one provider in the file OAUTH_PROVIDERS_FILE points
+// to. Name is the part of /auth/{name} the provider is reached by and
+// defaults to Type. Issuer is the discovery URL of an oidc provider, for
+// example https://accounts.example.com/.well-known/openid-configuration.
+// AuthURL, TokenURL and ProfileURL point github and gitlab to a self-hosted
+// instance. TrustEmail says that the provider only hands out emails it has
+// verified; without it emails count as verified only when the provider
+// says so in the email_verified claim.
+type OAuthProvider struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Issuer       string   `json:"issuer,omitempty"`
+	AuthURL      string   `json:"auth_url,omitempty"`
+	TokenURL     string   `json:"token_url,omitempty"`
+	ProfileURL   string   `json:"profile_url,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	TrustEmail   bool     `json:"trust_email,omitempty"`
 }
 
-// StartGoogleOAuth initiates Google OAuth flow
-func StartGoogleOAuth(w http.ResponseWriter, r *http.Request) {
-	if _, err := gothic.GetProviderName(r); err != nil {
-		provider := r.URL.Path[len("/auth/"):]
-		if provider == "" {
-			http.Error(w, "Provider not selected", http.StatusBadRequest)
-			return
+// ErrUnknownProvider is returned for a provider that is not configured.
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// trustEmail keeps TrustEmail of the registered providers by name.
+var trustEmail = map[string]bool{}
+
+// InitOAuth registers the providers from OAUTH_PROVIDERS_FILE. Without the
+// file CLIENT_ID and CLIENT_SECRET set up Google, as before. Callbacks go
+// to baseURL/auth/{name}/callback.
+func InitOAuth(baseURL string) error {
+	providers, err := loadProviders()
+	if err != nil {
+		return err
+	}
+	var registered []goth.Provider
+	for _, config := range providers {
+		if config.Name == "" {
+			config.Name = config.Type
 		}
-		r.URL.RawQuery = "provider=" + provider
+		if _, ok := trustEmail[config.Name]; ok {
+			return fmt.Errorf("provider %q is listed twice", config.Name)
+		}
+		provider, err := newProvider(config, baseURL+"/auth/"+config.Name+"/callback")
+		if err != nil {
+			return fmt.Errorf("provider %q: %v", config.Name, err)
+		}
+		trustEmail[config.Name] = config.TrustEmail
+		registered = append(registered, provider)
 	}
-	gothic.BeginAuthHandler(w, r)
+	goth.UseProviders(registered...)
+	return nil
 }
 
-// CompleteGoogleOAuth completes the Google OAuth flow
-func CompleteGoogleOAuth(w http.ResponseWriter, r *http.Request) (goth.User, error) {
-	user, err := gothic.CompleteUserAuth(w, r)
-	return user, err
+func loadProviders() ([]OAuthProvider, error) {
+	path := os.Getenv("OAUTH_PROVIDERS_FILE")
+	if path == "" {
+		if os.Getenv("CLIENT_ID") == "" {
+			return nil, nil
+		}
+		return []OAuthProvider{{
+			Type:         ProviderGoogle,
+			ClientID:     os.Getenv("CLIENT_ID"),
+			ClientSecret: os.Getenv("CLIENT_SECRET"),
+			TrustEmail:   true,
+		}}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error during reading oauth providers: %v", err)
+	}
+	var providers []OAuthProvider
+	if err = json.Unmarshal(data, &providers); err != nil {
+		return nil, fmt.Errorf("error during parsing oauth providers: %v", err)
+	}
+	return providers, nil
+}
+
+func newProvider(config OAuthProvider, callbackURL string) (goth.Provider, error) {
+	if config.ClientID == "" {
+		return nil, errors.New("client_id is required")
+	}
+	switch config.Type {
+	case ProviderGoogle:
+		provider := google.New(config.ClientID, config.ClientSecret, callbackURL, config.Scopes...)
+		provider.SetName(config.Name)
+		return provider, nil
+	case ProviderGitHub:
+		provider := github.New(config.ClientID, config.ClientSecret, callbackURL, config.Scopes...)
+		if config.AuthURL != "" {
+			provider = github.NewCustomisedURL(config.ClientID, config.ClientSecret, callbackURL,
+				config.AuthURL, config.TokenURL, config.ProfileURL, config.ProfileURL+"/emails", config.Scopes...)
+		}
+		provider.SetName(config.Name)
+		return provider, nil
+	case ProviderGitLab:
+		provider := gitlab.New(config.ClientID, config.ClientSecret, callbackURL, config.Scopes...)
+		if config.AuthURL != "" {
+			provider = gitlab.NewCustomisedURL(config.ClientID, config.ClientSecret, callbackURL,
+				config.AuthURL, config.TokenURL, config.ProfileURL, config.Scopes...)
+		}
+		provider.SetName(config.Name)
+		return provider, nil
+	case ProviderOIDC:
+		if config.Issuer == "" {
+			return nil, errors.New("issuer is required")
+		}
+		scopes := config.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+		return openidConnect.NewNamed(config.Name, config.ClientID, config.ClientSecret, callbackURL, config.Issuer, scopes...)
+	}
+	return nil, fmt.Errorf("unsupported type %q", config.Type)
+}
+
+// OAuthProviders returns the names of the registered providers.
+func OAuthProviders() []string {
+	names := make([]string, 0, len(trustEmail))
+	for name := range trustEmail {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartOAuth redirects the user to the provider.
+func StartOAuth(w http.ResponseWriter, r *http.Request, provider string) error {
+	if _, err := goth.GetProvider(provider); err != nil {
+		return ErrUnknownProvider
+	}
+	gothic.BeginAuthHandler(w, gothic.GetContextWithProvider(r, provider))
+	return nil
+}
+
+// CompleteOAuth finishes the flow when the provider redirects back.
+func CompleteOAuth(w http.ResponseWriter, r *http.Request, provider string) (goth.User, error) {
+	if _, err := goth.GetProvider(provider); err != nil {
+		return goth.User{}, ErrUnknownProvider
+	}
+	return gothic.CompleteUserAuth(w, gothic.GetContextWithProvider(r, provider))
+}
+
+// EmailVerified tells if the provider vouches for the email of the user.
+func EmailVerified(user goth.User) bool {
+	if user.Email == "" {
+		return false
+	}
+	// Google calls the claim verified_email, OIDC providers email_verified.
+	for _, claim := range []string{"email_verified", "verified_email"} {
+		if verified, ok := user.RawData[claim].(bool); ok {
+			return verified
+		}
+	}
+	return trustEmail[user.Provider]
 }
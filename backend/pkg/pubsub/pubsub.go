@@ -0,0 +1,120 @@
+// Package pubsub passes events from the services to the clients streaming
+// them. Hub keeps everything in the process, so every instance of the app
+// only sees its own events. A message broker can take its place behind
+// Broker once the app runs on more than one instance.
+package pubsub
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Event is one message on a topic. ID grows with every event of the
+// process and lets clients notice what they missed.
+type Event struct {
+	ID   uint64      `json:"-"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+type Broker interface {
+	Publish(topic string, event Event)
+	Subscribe(topics ...string) *Subscription
+}
+
+// Types of events.
+const (
+	CommentCreated = "comment.created"
+	CommentEdited  = "comment.edited"
+	CommentDeleted = "comment.deleted"
+	VotesChanged   = "votes.changed"
+	Notification   = "notification"
+)
+
+// DiscussionTopic carries the changes of comments and votes in the
+// discussion.
+func DiscussionTopic(discussionID string) string {
+	return "discussion:" + discussionID
+}
+
+// UserTopic carries the notifications of the user.
+func UserTopic(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+// bufferSize is how many events a subscriber can fall behind. Events for
+// a subscriber with a full buffer are dropped, a slow client must not
+// hold up the services.
+const bufferSize = 64
+
+// Subscription receives the events of its topics on Events until it is
+// closed.
+type Subscription struct {
+	Events  <-chan Event
+	events  chan Event
+	topics  []string
+	hub     *Hub
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// Dropped tells how many events did not fit into the buffer.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() { s.hub.unsubscribe(s) })
+}
+
+type Hub struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription]bool
+	lastID atomic.Uint64
+}
+
+func NewHub() *Hub {
+	return &Hub{topics: map[string]map[*Subscription]bool{}}
+}
+
+func (h *Hub) Subscribe(topics ...string) *Subscription {
+	events := make(chan Event, bufferSize)
+	sub := &Subscription{Events: events, events: events, topics: topics, hub: h}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = map[*Subscription]bool{}
+		}
+		h.topics[topic][sub] = true
+	}
+	return sub
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range sub.topics {
+		delete(h.topics[topic], sub)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	close(sub.events)
+}
+
+// Publish hands the event to every subscriber of the topic without
+// waiting for any of them.
+func (h *Hub) Publish(topic string, event Event) {
+	event.ID = h.lastID.Add(1)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.topics[topic] {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how often MemoryStore forgets buckets that refilled.
+const sweepEvery = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket is full again and no longer worth keeping.
+	full time.Time
+}
+
+// MemoryStore keeps the buckets of one instance of the app.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, lastSweep: time.Now()}
+}
+
+func (m *MemoryStore) Take(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.lastSweep) > sweepEvery {
+		for k, b := range m.buckets {
+			if now.After(b.full) {
+				delete(m.buckets, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.burst(), updated: now}
+		m.buckets[key] = b
+	}
+	var wait time.Duration
+	b.tokens, wait = take(b.tokens, now.Sub(b.updated), limit)
+	b.updated = now
+	b.full = now.Add(time.Duration((limit.burst() - b.tokens) / limit.rate() * float64(time.Second)))
+	return wait, nil
+}
@@ -0,0 +1,149 @@
+// Package ratelimit keeps token buckets for groups of routes. Every client
+// of a group has its own bucket that holds up to Burst requests and refills
+// with Requests every Per. The buckets live in a Store, chosen by
+// RATE_LIMIT_DRIVER: "memory" (the default) keeps them in the process,
+// "redis" keeps them in the Redis compatible server at REDIS_URL, so that
+// every instance of the app shares them.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Groups of routes with their own limits.
+const (
+	GroupAuth = "auth"
+	GroupPost = "post"
+	GroupVote = "vote"
+	GroupEdit = "edit"
+)
+
+// Limit is the bucket of a group. Burst defaults to Requests.
+type Limit struct {
+	Requests int      `json:"requests"`
+	Per      Duration `json:"per"`
+	Burst    int      `json:"burst,omitempty"`
+}
+
+// Duration reads durations like "1m" from json.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// rate is how many tokens come back every second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / time.Duration(l.Per).Seconds()
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// DefaultLimits apply to groups RATE_LIMITS_FILE does not mention.
+var DefaultLimits = map[string]Limit{
+	GroupAuth: {Requests: 10, Per: Duration(time.Minute)},
+	GroupPost: {Requests: 10, Per: Duration(time.Minute), Burst: 5},
+	GroupVote: {Requests: 60, Per: Duration(time.Minute), Burst: 20},
+	GroupEdit: {Requests: 30, Per: Duration(time.Minute), Burst: 10},
+}
+
+// Store keeps the buckets. Take takes a token from the bucket under key and
+// returns zero, or returns how long to wait for the next token when the
+// bucket is empty.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (time.Duration, error)
+}
+
+// take refills a bucket that had tokens elapsed ago and takes one token
+// from it. It returns the tokens left and how long to wait when there was
+// none.
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, time.Duration) {
+	tokens = math.Min(limit.burst(), tokens+elapsed.Seconds()*limit.rate())
+	if tokens >= 1 {
+		return tokens - 1, 0
+	}
+	return tokens, time.Duration((1 - tokens) / limit.rate() * float64(time.Second))
+}
+
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+}
+
+func NewLimiter(store Store, limits map[string]Limit) *Limiter {
+	return &Limiter{store: store, limits: limits}
+}
+
+// Allow takes a request of the client from the bucket of the group. It
+// returns zero when the request may go on, or how long the client has to
+// wait. Groups without a limit are not limited.
+func (l *Limiter) Allow(ctx context.Context, group, client string) (time.Duration, error) {
+	limit, ok := l.limits[group]
+	if !ok || limit.Requests <= 0 {
+		return 0, nil
+	}
+	return l.store.Take(ctx, group+":"+client, limit)
+}
+
+// FromEnv builds the limiter from RATE_LIMIT_DRIVER and the limits in the
+// json file RATE_LIMITS_FILE points to, for example
+//
+//	{"post": {"requests": 5, "per": "1m", "burst": 2}, "vote": {"requests": 0}}
+//
+// A group with no requests is not limited.
+func FromEnv() (*Limiter, error) {
+	limits := make(map[string]Limit, len(DefaultLimits))
+	for group, limit := range DefaultLimits {
+		limits[group] = limit
+	}
+	if path := os.Getenv("RATE_LIMITS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error during reading rate limits: %v", err)
+		}
+		var configured map[string]Limit
+		if err = json.Unmarshal(data, &configured); err != nil {
+			return nil, fmt.Errorf("error during parsing rate limits: %v", err)
+		}
+		for group, limit := range configured {
+			if limit.Requests > 0 && limit.Per <= 0 {
+				return nil, fmt.Errorf("rate limit %q: per is required", group)
+			}
+			limits[group] = limit
+		}
+	}
+
+	var store Store
+	switch driver := os.Getenv("RATE_LIMIT_DRIVER"); driver {
+	case "", "memory":
+		store = NewMemoryStore()
+	case "redis":
+		redisStore, err := NewRedisStore(os.Getenv("REDIS_URL"))
+		if err != nil {
+			return nil, err
+		}
+		store = redisStore
+	default:
+		return nil, fmt.Errorf("unknown rate limit driver %q", driver)
+	}
+	return NewLimiter(store, limits), nil
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	retryAttempts = 3
+	retryWait     = 100 * time.Millisecond
+)
+
+// Retry calls f until it succeeds, up to three times, waiting twice as
+// long after every failure. It returns the last error, or the error of ctx
+// if it is done first. f has to be safe to call again after it failed.
+func Retry(ctx context.Context, f func() error) error {
+	wait := retryWait
+	var err error
+	for attempt := 0; attempt < retryAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+		if err = f(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
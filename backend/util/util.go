@@ -18,6 +18,37 @@ func ValidateTitle(title string) error {
 	return nil
 }
 
+var validTag = regexp.MustCompile(`^[a-z0-9][a-z0-9+#.-]{1,24}$`)
+
+func ValidateTag(tag string) error {
+	if !validTag.MatchString(tag) {
+		return fmt.Errorf("tag %q must be 2-25 lowercase letters, digits or one of '+#.-' and start with a letter or digit", tag)
+	}
+	return nil
+}
+
+// ParseTags splits a comma separated list of tags and normalizes it with
+// NormalizeTags.
+func ParseTags(raw string) []string {
+	return NormalizeTags(strings.Split(raw, ","))
+}
+
+// NormalizeTags turns every tag to lower case and drops empty and repeated
+// ones.
+func NormalizeTags(raw []string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range raw {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
 var adjectives = []string{"Swift", "Mighty", "Brave", "Clever", "Silent", "Fierce", "Bright", "Shadow", "Lunar", "Wild"}
 var nouns = []string{"Tiger", "Eagle", "Wizard", "Knight", "Panther", "Phoenix", "Dragon", "Wolf", "Hawk", "Fox"}
 
@@ -28,3 +59,27 @@ func GenerateNickname() string {
 
 	return fmt.Sprintf("%s%s%d", adjective, noun, number)
 }
+
+var mention = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_][A-Za-z0-9_.-]{0,49})`)
+
+// maxMentions is how many users one text can mention.
+const maxMentions = 10
+
+// ParseMentions returns the lower-cased usernames mentioned in the text
+// with @username, each once.
+func ParseMentions(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range mention.FindAllStringSubmatch(text, -1) {
+		name := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		if len(names) == maxMentions {
+			break
+		}
+	}
+	return names
+}